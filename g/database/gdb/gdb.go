@@ -30,9 +30,6 @@ const (
 
 // 数据库操作接口
 type Link interface {
-	// 打开数据库连接，建立数据库操作对象
-	Open(c *ConfigNode) (*sql.DB, error)
-
 	// SQL操作方法
 	Query(q string, args ...interface{}) (*sql.Rows, error)
 	Exec(q string, args ...interface{}) (sql.Result, error)
@@ -75,26 +72,20 @@ type Link interface {
 
 	// 关闭数据库操作对象
 	Close() error
-
-	// 内部方法
-	insert(table string, data Map, option uint8) (sql.Result, error)
-	batchInsert(table string, list List, batch int, option uint8) (sql.Result, error)
-
-	getQuoteCharLeft() string
-	getQuoteCharRight() string
-	handleSqlBeforeExec(q *string) *string
 }
 
 // 数据库链接对象
 type Db struct {
-	link   Link          // 底层数据库类型管理对象
+	driver Driver        // 数据库驱动(处理不同数据库的SQL方言)
+	group  string        // 数据库配置分组名称
 	master *sql.DB       // 实例化数据库链接(master)
-	slave  *sql.DB       // 实例化数据库链接(slave，可能会与master相同)
+	slaves []*dbSlave    // 实例化数据库链接(slave)，读操作按照权重选择健康的节点，为空时使用master
 	charl  string        // SQL安全符号(左)
 	charr  string        // SQL安全符号(右)
 	debug  *gtype.Bool   // (默认关闭)是否开启调试模式，当开启时会启用一些调试特性
 	sqls   *gring.Ring   // (debug=true时有效)已执行的SQL列表
 	cache  *gcache.Cache // 查询缓存，需要注意的是，事务查询不支持缓存
+	keys   *gmap.StringInterfaceMap // 数据表主键字段缓存(Save操作使用)
 }
 
 // 执行的SQL对象
@@ -122,16 +113,6 @@ type Map = map[string]interface{}
 // 关联数组列表(索引从0开始的数组)，绑定多条记录(使用别名)
 type List = []Map
 
-// MySQL接口对象
-var linkMysql = &dbmysql{}
-
-// PostgreSQL接口对象
-var linkPgsql = &dbpgsql{}
-
-// Sqlite接口对象
-// @author wxkj<wxscz@qq.com>
-var linkSqlite = &dbsqlite{}
-
 // 数据库查询缓存对象map，使用数据库连接名称作为键名，键值为查询缓存对象
 var dbCaches = gmap.NewStringInterfaceMap()
 
@@ -162,12 +143,9 @@ func New(groupName ...string) (*Db, error) {
 		if len(masterList) < 1 {
 			return nil, errors.New("at least one master node configuration's need to make sense")
 		}
+		// master按照优先级选择一个节点，slave保留所有节点，每次读操作按照优先级(权重)选择健康的节点
 		masterNode := getConfigNodeByPriority(masterList)
-		var slaveNode *ConfigNode
-		if len(slaveList) > 0 {
-			slaveNode = getConfigNodeByPriority(slaveList)
-		}
-		return newDb(masterNode, slaveNode, name)
+		return newDb(masterNode, slaveList, name)
 	} else {
 		return nil, errors.New(fmt.Sprintf("empty database configuration for item name '%s'", name))
 	}
@@ -207,36 +185,37 @@ func getConfigNodeByPriority(cg ConfigGroup) *ConfigNode {
 }
 
 // 创建数据库链接对象
-func newDb(masterNode *ConfigNode, slaveNode *ConfigNode, groupName string) (*Db, error) {
-	var link Link
-	switch masterNode.Type {
-		case "mysql":
-			link = linkMysql
-		case "pgsql":
-			link = linkPgsql
-		case "sqlite":
-			link = linkSqlite
-		default:
-			return nil, errors.New(fmt.Sprintf("unsupported db type '%s'", masterNode.Type))
+func newDb(masterNode *ConfigNode, slaveNodes ConfigGroup, groupName string) (*Db, error) {
+	driver, err := getDriver(masterNode.Type)
+	if err != nil {
+		return nil, err
 	}
-	master, err := link.Open(masterNode)
+	master, err := driver.Open(masterNode)
 	if err != nil {
 		return nil, err
 	}
-	slave := master
-	if slaveNode != nil {
-		slave, err = link.Open(slaveNode)
+	slaves := make([]*dbSlave, 0, len(slaveNodes))
+	for i := 0; i < len(slaveNodes); i++ {
+		slave, err := newDbSlave(driver, &slaveNodes[i])
 		if err != nil {
+			// 关闭已经创建的链接，并释放从库健康检查
+			master.Close()
+			for _, v := range slaves {
+				v.close()
+			}
 			return nil, err
 		}
+		slaves = append(slaves, slave)
 	}
 	db := &Db{
-		link:   link,
+		driver: driver,
+		group:  groupName,
 		master: master,
-		slave:  slave,
-		charl:  link.getQuoteCharLeft(),
-		charr:  link.getQuoteCharRight(),
+		slaves: slaves,
+		charl:  driver.GetQuoteCharLeft(),
+		charr:  driver.GetQuoteCharRight(),
 		debug:  gtype.NewBool(),
+		keys:   gmap.NewStringInterfaceMap(),
 	}
 	// 设置连接属性，master和slave必须是一致的，所以这里使用的是master的属性设置
 	if masterNode.MaxIdleConnCount > 0 {
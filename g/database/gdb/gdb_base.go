@@ -89,24 +89,29 @@ func (db *Db) Close() error {
             return err
         }
     }
-    if db.slave != nil {
-        if err := db.slave.Close(); err == nil {
-            db.slave = nil
-        } else {
+    for len(db.slaves) > 0 {
+        if err := db.slaves[0].close(); err != nil {
             return err
         }
+        db.slaves = db.slaves[1:]
     }
     return nil
 }
 
-// 数据库sql查询操作，主要执行查询
+// 数据库sql查询操作，主要执行查询，查询按照权重分配到健康的slave节点执行
 func (db *Db) Query(query string, args ...interface{}) (*sql.Rows, error) {
+    return db.doQuery(db.getSlave(), query, args...)
+}
+
+// 使用指定的数据库链接执行sql查询操作
+func (db *Db) doQuery(link *sql.DB, query string, args ...interface{}) (*sql.Rows, error) {
     var err  error
     var rows *sql.Rows
-    p := db.link.handleSqlBeforeExec(&query)
+    p := db.driver.HandleSqlBeforeExec(&query)
+    start := time.Now()
     if db.debug.Val() {
         militime1 := gtime.Millisecond()
-        rows, err  = db.slave.Query(*p, args ...)
+        rows, err  = link.Query(*p, args ...)
         militime2 := gtime.Millisecond()
         s := &Sql{
             Sql   : *p,
@@ -119,8 +124,9 @@ func (db *Db) Query(query string, args ...interface{}) (*sql.Rows, error) {
         db.sqls.Put(s)
         db.printSql(s)
     } else {
-        rows, err = db.slave.Query(*p, args ...)
+        rows, err = link.Query(*p, args ...)
     }
+    db.addStats("DB:Query", start, err)
     if err == nil {
         return rows, nil
     } else {
@@ -133,7 +139,8 @@ func (db *Db) Query(query string, args ...interface{}) (*sql.Rows, error) {
 func (db *Db) Exec(query string, args ...interface{}) (sql.Result, error) {
     var err    error
     var result sql.Result
-    p := db.link.handleSqlBeforeExec(&query)
+    p := db.driver.HandleSqlBeforeExec(&query)
+    start := time.Now()
     if db.debug.Val() {
         militime1  := gtime.Millisecond()
         result, err = db.master.Exec(*p, args ...)
@@ -151,6 +158,7 @@ func (db *Db) Exec(query string, args ...interface{}) (sql.Result, error) {
     } else {
         result, err = db.master.Exec(*p, args ...)
     }
+    db.addStats("DB:Exec", start, err)
     return result, db.formatError(err, p, args...)
 }
 
@@ -170,8 +178,13 @@ func (db *Db) formatError(err error, query *string, args ...interface{}) error {
 
 // 数据库查询，获取查询结果集，以列表结构返回
 func (db *Db) GetAll(query string, args ...interface{}) (Result, error) {
+    return db.doGetAll(db.getSlave(), query, args...)
+}
+
+// 使用指定的数据库链接查询，获取查询结果集
+func (db *Db) doGetAll(link *sql.DB, query string, args ...interface{}) (Result, error) {
     // 执行sql
-    rows, err := db.Query(query, args ...)
+    rows, err := db.doQuery(link, query, args ...)
     if err != nil || rows == nil {
         return nil, err
     }
@@ -196,6 +209,11 @@ func (db *Db) GetAll(query string, args ...interface{}) (Result, error) {
         // 注意col字段是一个[]byte类型(slice类型本身是一个指针)，多个记录循环时该变量指向的是同一个内存地址
         for i, col := range values {
             k := columns[i]
+            // NULL值保留为nil，以便判断(Value.IsNil)及映射到指针/sql.Null*类型的属性
+            if col == nil {
+                row[k] = nil
+                continue
+            }
             v := make([]byte, len(col))
             copy(v, col)
             row[k] = v
@@ -226,6 +244,15 @@ func (db *Db) GetStruct(obj interface{}, query string, args ...interface{}) erro
     return one.ToStruct(obj)
 }
 
+// 数据库查询，获取查询结果集，自动映射数据到给定的struct数组中，objs为数组指针，如：&[]User或者&[]*User
+func (db *Db) GetStructs(objs interface{}, query string, args ...interface{}) error {
+    list, err := db.GetAll(query, args...)
+    if err != nil {
+        return err
+    }
+    return list.ToStructs(objs)
+}
+
 
 // 数据库查询，获取查询字段值
 func (db *Db) GetValue(query string, args ...interface{}) (Value, error) {
@@ -261,7 +288,7 @@ func (db *Db) Select(tables, fields string, condition interface{}, groupBy, orde
         s += fmt.Sprintf("ORDER BY %s ", orderBy)
     }
     if limit > 0 {
-        s += fmt.Sprintf("LIMIT %d,%d ", first, limit)
+        s = db.driver.HandleLimit(strings.TrimSpace(s), first, limit)
     }
     return db.GetAll(s, args ... )
 }
@@ -278,27 +305,36 @@ func (db *Db) PingMaster() error {
     return err
 }
 
-// ping一下，判断或保持数据库链接(slave)
+// ping一下，判断或保持数据库链接(所有slave节点，没有slave节点时为master)，
+// 同时更新slave节点的健康状态，返回第一个失败节点的错误
 func (db *Db) PingSlave() error {
-    err := db.slave.Ping()
-    return err
+    if len(db.slaves) == 0 {
+        return db.master.Ping()
+    }
+    var result error
+    for _, v := range db.slaves {
+        err := v.link.Ping()
+        v.healthy.Set(err == nil)
+        if err != nil && result == nil {
+            result = err
+        }
+    }
+    return result
 }
 
 // 设置数据库连接池中空闲链接的大小
 func (db *Db) SetMaxIdleConns(n int) {
     db.master.SetMaxIdleConns(n)
-    // 比较的是指向的变量地址
-    if db.master != db.slave {
-        db.slave.SetMaxIdleConns(n)
+    for _, v := range db.slaves {
+        v.link.SetMaxIdleConns(n)
     }
 }
 
 // 设置数据库连接池最大打开的链接数量
 func (db *Db) SetMaxOpenConns(n int) {
     db.master.SetMaxOpenConns(n)
-    // 比较的是指向的变量地址
-    if db.master != db.slave {
-        db.slave.SetMaxOpenConns(n)
+    for _, v := range db.slaves {
+        v.link.SetMaxOpenConns(n)
     }
 }
 
@@ -306,9 +342,8 @@ func (db *Db) SetMaxOpenConns(n int) {
 // 如果 d <= 0 表示该链接会一直重复利用
 func (db *Db) SetConnMaxLifetime(d time.Duration) {
     db.master.SetConnMaxLifetime(d)
-    // 比较的是指向的变量地址
-    if db.master != db.slave {
-        db.slave.SetConnMaxLifetime(d)
+    for _, v := range db.slaves {
+        v.link.SetConnMaxLifetime(d)
     }
 }
 
@@ -324,54 +359,14 @@ func (db *Db) Begin() (*Tx, error) {
     }
 }
 
-// 根据insert选项获得操作名称
-func (db *Db) getInsertOperationByOption(option uint8) string {
-    oper := "INSERT"
-    switch option {
-        case OPTION_REPLACE:
-            oper = "REPLACE"
-        case OPTION_SAVE:
-        case OPTION_IGNORE:
-            oper = "INSERT IGNORE"
-    }
-    return oper
-}
-
 // insert、replace, save， ignore操作
 // 0: insert:  仅仅执行写入操作，如果存在冲突的主键或者唯一索引，那么报错返回
 // 1: replace: 如果数据存在(主键或者唯一索引)，那么删除后重新写入一条
 // 2: save:    如果数据存在(主键或者唯一索引)，那么更新，否则写入一条新数据
 // 3: ignore:  如果数据存在(主键或者唯一索引)，那么什么也不做
-func (db *Db) insert(table string, data Map, option uint8) (sql.Result, error) {
-    var fields []string
-    var values []string
-    var params []interface{}
-    for k, v := range data {
-        fields = append(fields,   db.charl + k + db.charr)
-        values = append(values, "?")
-        params = append(params, gconv.String(v))
-    }
-    operation := db.getInsertOperationByOption(option)
-    updatestr := ""
-    if option == OPTION_SAVE {
-        var updates []string
-        for k, _ := range data {
-            updates = append(updates,
-                fmt.Sprintf("%s%s%s=VALUES(%s%s%s)",
-                    db.charl, k, db.charr,
-                    db.charl, k, db.charr,
-                ),
-            )
-        }
-        updatestr = fmt.Sprintf("ON DUPLICATE KEY UPDATE %s", strings.Join(updates, ","))
-    }
-    return db.Exec(
-        fmt.Sprintf("%s INTO %s%s%s(%s) VALUES(%s) %s",
-            operation, db.charl, table, db.charr, strings.Join(fields, ","),
-            strings.Join(values, ","),
-            updatestr),
-            params...
-    )
+// 不同数据库的SQL语法由驱动生成，conflict为Save操作判断冲突的字段(PostgreSQL/SQLite)，为空时使用主键
+func (db *Db) insert(table string, data Map, option uint8, conflict ...string) (sql.Result, error) {
+    return db.batchInsert(table, List{data}, 1, option, conflict...)
 }
 
 // CURD操作:单条数据写入, 仅仅执行写入操作，如果存在冲突的主键或者唯一索引，那么报错返回
@@ -390,68 +385,16 @@ func (db *Db) Save(table string, data Map) (sql.Result, error) {
 }
 
 // 批量写入数据
-func (db *Db) batchInsert(table string, list List, batch int, option uint8) (sql.Result, error) {
-    var keys    []string
-    var values  []string
-    var bvalues []string
-    var params  []interface{}
-    var result  sql.Result
-    var size = len(list)
-    // 判断长度
-    if size < 1 {
-        return result, errors.New("empty data list")
-    }
-    // 首先获取字段名称及记录长度
-    for k, _ := range list[0] {
-        keys   = append(keys,   k)
-        values = append(values, "?")
-    }
-    keyStr         := db.charl + strings.Join(keys, db.charl + "," + db.charr) + db.charr
-    valueHolderStr := "(" + strings.Join(values, ",") + ")"
-    // 操作判断
-    operation := db.getInsertOperationByOption(option)
-    updatestr := ""
-    if option == OPTION_SAVE {
-        var updates []string
-        for _, k := range keys {
-            updates = append(updates,
-                fmt.Sprintf("%s%s%s=VALUES(%s%s%s)",
-                    db.charl, k, db.charr,
-                    db.charl, k, db.charr,
-                ),
-            )
-        }
-        updatestr = fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s", strings.Join(updates, ","))
-    }
-    // 构造批量写入数据格式(注意map的遍历是无序的)
-    for i := 0; i < size; i++ {
-        for _, k := range keys {
-            params = append(params, gconv.String(list[i][k]))
-        }
-        bvalues = append(bvalues, valueHolderStr)
-        if len(bvalues) == batch {
-            r, err := db.Exec(fmt.Sprintf("%s INTO %s%s%s(%s) VALUES%s %s",
-                operation, db.charl, table, db.charr, keyStr, strings.Join(bvalues, ","),
-                updatestr),
-                params...)
-            if err != nil {
-                return result, err
-            }
-            result  = r
-            params  = params[:0]
-            bvalues = bvalues[:0]
-        }
+func (db *Db) batchInsert(table string, list List, batch int, option uint8, conflict ...string) (sql.Result, error) {
+    var result sql.Result
+    sqls, err := db.buildInsertSqls(table, list, batch, option, conflict)
+    if err != nil {
+        return result, err
     }
-    // 处理最后不构成指定批量的数据
-    if len(bvalues) > 0 {
-        r, err := db.Exec(fmt.Sprintf("%s INTO %s%s%s(%s) VALUES%s %s",
-            operation, db.charl, table, db.charr, keyStr, strings.Join(bvalues, ","),
-            updatestr),
-            params...)
-        if err != nil {
+    for _, v := range sqls {
+        if result, err = db.Exec(v.sql, v.args...); err != nil {
             return result, err
         }
-        result = r
     }
     return result, nil
 }
@@ -481,8 +424,8 @@ func (db *Db) Update(table string, data interface{}, condition interface{}, args
         var fields []string
         keys := refValue.MapKeys()
         for _, k := range keys {
-            fields = append(fields, fmt.Sprintf("%s%s%s=?", db.charl, k, db.charr))
-            params = append(params, gconv.String(refValue.MapIndex(k).Interface()))
+            fields = append(fields, db.quoteWord(gconv.String(k.Interface())) + "=?")
+            params = append(params, convertParam(refValue.MapIndex(k).Interface()))
         }
         updates = strings.Join(fields, ",")
     } else {
@@ -491,12 +434,24 @@ func (db *Db) Update(table string, data interface{}, condition interface{}, args
     for _, v := range args {
         params = append(params, gconv.String(v))
     }
-    return db.Exec(fmt.Sprintf("UPDATE %s%s%s SET %s WHERE %s", db.charl, table, db.charr, updates, db.formatCondition(condition)), params...)
+    return db.Exec(fmt.Sprintf("UPDATE %s SET %s WHERE %s", db.quoteWord(table), updates, db.formatCondition(condition)), params...)
 }
 
 // CURD操作:删除数据
 func (db *Db) Delete(table string, condition interface{}, args ...interface{}) (sql.Result, error) {
-    return db.Exec(fmt.Sprintf("DELETE FROM %s%s%s WHERE %s", db.charl, table, db.charr, db.formatCondition(condition)), args...)
+    return db.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s", db.quoteWord(table), db.formatCondition(condition)), args...)
+}
+
+// 转换写入的参数值，struct映射中的NULL值(ormNull)写入NULL，[]byte原样写入，其他值转换为字符串，
+// 注意Map数据中的nil值与之前的版本保持一致，转换为空字符串写入
+func convertParam(value interface{}) interface{} {
+    switch v := value.(type) {
+        case ormNullValue:
+            return nil
+        case []byte:
+            return v
+    }
+    return gconv.String(value)
 }
 
 // 格式化SQL查询条件
@@ -0,0 +1,155 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 写入SQL语句生成(insert/replace/save/ignore在不同数据库下的方言处理).
+
+package gdb
+
+import (
+    "fmt"
+    "sort"
+    "errors"
+    "strings"
+    "database/sql"
+)
+
+// 可选的驱动接口，用于获取数据表的主键字段。
+// PostgreSQL/SQLite的Save(ON CONFLICT)操作需要指定冲突字段，没有通过Model.OnConflict指定时使用数据表主键。
+type PrimaryKeyDriver interface {
+    GetPrimaryKeys(db *sql.DB, table string) ([]string, error)
+}
+
+// 写入SQL语句及对应的参数
+type insertSql struct {
+    sql  string
+    args []interface{}
+}
+
+// 生成写入SQL语句，list中的记录每batch条生成一条SQL语句，字段以第一条记录为准(按照名称排序)，
+// conflict为Save操作判断冲突的字段(未转义)，为空时使用数据表主键(驱动支持时)。
+func (db *Db) buildInsertSqls(table string, list List, batch int, option uint8, conflict []string) ([]insertSql, error) {
+    if len(list) < 1 {
+        return nil, errors.New("empty data list")
+    }
+    if batch < 1 {
+        batch = len(list)
+    }
+    // 首先获取字段名称(注意map的遍历是无序的，排序后保证生成的SQL一致)
+    fields := make([]string, 0, len(list[0]))
+    for k, _ := range list[0] {
+        fields = append(fields, k)
+    }
+    sort.Strings(fields)
+    if len(conflict) == 0 && option == OPTION_SAVE {
+        if keys, err := db.getPrimaryKeys(table); err != nil {
+            return nil, err
+        } else {
+            conflict = keys
+        }
+    }
+    keys    := db.quoteFields(fields)
+    holders := "(" + strings.TrimRight(strings.Repeat("?,", len(fields)), ",") + ")"
+    sqls    := make([]insertSql, 0)
+    values  := make([]string, 0)
+    params  := make([]interface{}, 0)
+    for i := 0; i < len(list); i++ {
+        for _, k := range fields {
+            params = append(params, convertParam(list[i][k]))
+        }
+        values = append(values, holders)
+        if len(values) == batch || i == len(list) - 1 {
+            s, err := db.handleInsert(db.quoteWord(table), keys, values, option, db.quoteFields(conflict))
+            if err != nil {
+                return nil, err
+            }
+            sqls   = append(sqls, insertSql{s, params})
+            values = make([]string, 0)
+            params = make([]interface{}, 0)
+        }
+    }
+    return sqls, nil
+}
+
+// 生成写入SQL，驱动没有实现InsertDriver接口时只支持Insert操作
+func (db *Db) handleInsert(table string, keys []string, values []string, option uint8, conflict []string) (string, error) {
+    if handler, ok := db.driver.(InsertDriver); ok {
+        return handler.HandleInsert(table, keys, values, option, conflict)
+    }
+    if option != OPTION_INSERT {
+        return "", unsupportedInsertOption(fmt.Sprintf("%T", db.driver), option)
+    }
+    return buildInsertSql("INSERT", table, keys, values, ""), nil
+}
+
+// 获取数据表主键字段(驱动需要实现PrimaryKeyDriver接口)，结果会被缓存
+func (db *Db) getPrimaryKeys(table string) ([]string, error) {
+    getter, ok := db.driver.(PrimaryKeyDriver)
+    if !ok {
+        return nil, nil
+    }
+    if v := db.keys.Get(table); v != nil {
+        return v.([]string), nil
+    }
+    keys, err := getter.GetPrimaryKeys(db.master, table)
+    if err != nil {
+        return nil, err
+    }
+    db.keys.Set(table, keys)
+    return keys, nil
+}
+
+// 对表名或者字段名称进行转义，驱动实现了quoteWordHandler接口时先由驱动进行处理
+func (db *Db) quoteWord(word string) string {
+    if handler, ok := db.driver.(quoteWordHandler); ok {
+        word = handler.HandleQuoteWord(word)
+    }
+    return db.charl + word + db.charr
+}
+
+// 对字段名称进行转义
+func (db *Db) quoteFields(fields []string) []string {
+    quoted := make([]string, len(fields))
+    for i, v := range fields {
+        quoted[i] = db.quoteWord(v)
+    }
+    return quoted
+}
+
+// 生成基本的写入SQL：operation INTO table(keys) VALUES(...),(...)suffix
+func buildInsertSql(operation string, table string, keys []string, values []string, suffix string) string {
+    return fmt.Sprintf("%s INTO %s(%s) VALUES%s%s", operation, table, strings.Join(keys, ","), strings.Join(values, ","), suffix)
+}
+
+// 生成ON CONFLICT更新语句(PostgreSQL/SQLite)，冲突字段不参与更新，没有需要更新的字段时不做任何操作
+func buildOnConflictSuffix(keys []string, conflict []string) (string, error) {
+    if len(conflict) == 0 {
+        return "", errors.New("conflict keys are required for upsert, please specify them using Model.OnConflict or define a primary key")
+    }
+    exist := make(map[string]struct{}, len(conflict))
+    for _, k := range conflict {
+        exist[k] = struct{}{}
+    }
+    updates := make([]string, 0, len(keys))
+    for _, k := range keys {
+        if _, ok := exist[k]; !ok {
+            updates = append(updates, fmt.Sprintf("%s=EXCLUDED.%s", k, k))
+        }
+    }
+    if len(updates) == 0 {
+        return fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(conflict, ",")), nil
+    }
+    return fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(conflict, ","), strings.Join(updates, ",")), nil
+}
+
+// 驱动不支持的写入操作
+func unsupportedInsertOption(driver string, option uint8) error {
+    name := "insert"
+    switch option {
+        case OPTION_REPLACE: name = "replace"
+        case OPTION_SAVE:    name = "save"
+        case OPTION_IGNORE:  name = "insert ignore"
+    }
+    return fmt.Errorf("%s operation is not supported by %s driver", name, driver)
+}
@@ -0,0 +1,201 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+// 不同数据库方言生成的SQL语句单元测试(不需要数据库连接)
+// go test *.go
+
+package gdb
+
+import (
+    "testing"
+    "database/sql"
+    "gitee.com/johng/gf/g/container/gmap"
+)
+
+// 只实现基本Driver接口的第三方驱动(没有实现InsertDriver接口)
+type builderTestDriver struct {}
+
+func (d *builderTestDriver) Open(c *ConfigNode) (*sql.DB, error) {
+    return nil, nil
+}
+
+func (d *builderTestDriver) GetQuoteCharLeft() string {
+    return "`"
+}
+
+func (d *builderTestDriver) GetQuoteCharRight() string {
+    return "`"
+}
+
+func (d *builderTestDriver) HandleSqlBeforeExec(q *string) *string {
+    return q
+}
+
+func (d *builderTestDriver) HandleLimit(q string, start, limit int) string {
+    return q
+}
+
+// 创建用于生成SQL的数据库对象，user表的主键为id
+func newBuilderTestDb(dbType string) *Db {
+    driver, err := getDriver(dbType)
+    if err != nil {
+        panic(err)
+    }
+    db := &Db {
+        driver : driver,
+        charl  : driver.GetQuoteCharLeft(),
+        charr  : driver.GetQuoteCharRight(),
+        keys   : gmap.NewStringInterfaceMap(),
+    }
+    db.keys.Set("user", []string{"id"})
+    return db
+}
+
+func Test_BuildInsertSqls(t *testing.T) {
+    one  := List{{"id" : 1, "name" : "john"}}
+    list := List{{"id" : 1, "name" : "john"}, {"id" : 2, "name" : "smith"}, {"id" : 3, "name" : "alice"}}
+    tests := []struct {
+        dbType   string
+        option   uint8
+        list     List
+        batch    int
+        conflict []string
+        sqls     []string
+        err      bool
+    } {
+        // mysql
+        {"mysql", OPTION_INSERT,  one,  1, nil, []string{"INSERT INTO `user`(`id`,`name`) VALUES(?,?)"}, false},
+        {"mysql", OPTION_REPLACE, one,  1, nil, []string{"REPLACE INTO `user`(`id`,`name`) VALUES(?,?)"}, false},
+        {"mysql", OPTION_IGNORE,  one,  1, nil, []string{"INSERT IGNORE INTO `user`(`id`,`name`) VALUES(?,?)"}, false},
+        {"mysql", OPTION_SAVE,    one,  1, nil, []string{"INSERT INTO `user`(`id`,`name`) VALUES(?,?) ON DUPLICATE KEY UPDATE `id`=VALUES(`id`),`name`=VALUES(`name`)"}, false},
+        {"mysql", OPTION_SAVE,    list, 2, nil, []string{
+            "INSERT INTO `user`(`id`,`name`) VALUES(?,?),(?,?) ON DUPLICATE KEY UPDATE `id`=VALUES(`id`),`name`=VALUES(`name`)",
+            "INSERT INTO `user`(`id`,`name`) VALUES(?,?) ON DUPLICATE KEY UPDATE `id`=VALUES(`id`),`name`=VALUES(`name`)",
+        }, false},
+        // pgsql
+        {"pgsql", OPTION_INSERT,  one,  1, nil, []string{`INSERT INTO "user"("id","name") VALUES(?,?)`}, false},
+        {"pgsql", OPTION_IGNORE,  one,  1, nil, []string{`INSERT INTO "user"("id","name") VALUES(?,?) ON CONFLICT DO NOTHING`}, false},
+        {"pgsql", OPTION_SAVE,    one,  1, nil, []string{`INSERT INTO "user"("id","name") VALUES(?,?) ON CONFLICT ("id") DO UPDATE SET "name"=EXCLUDED."name"`}, false},
+        {"pgsql", OPTION_REPLACE, one,  1, nil, nil, true},
+        {"pgsql", OPTION_SAVE,    one,  1, []string{"name"}, []string{`INSERT INTO "user"("id","name") VALUES(?,?) ON CONFLICT ("name") DO UPDATE SET "id"=EXCLUDED."id"`}, false},
+        {"pgsql", OPTION_SAVE,    one,  1, []string{"id", "name"}, []string{`INSERT INTO "user"("id","name") VALUES(?,?) ON CONFLICT ("id","name") DO NOTHING`}, false},
+        {"pgsql", OPTION_SAVE,    list, 10, nil, []string{`INSERT INTO "user"("id","name") VALUES(?,?),(?,?),(?,?) ON CONFLICT ("id") DO UPDATE SET "name"=EXCLUDED."name"`}, false},
+        // sqlite
+        {"sqlite", OPTION_INSERT,  one,  1, nil, []string{"INSERT INTO `user`(`id`,`name`) VALUES(?,?)"}, false},
+        {"sqlite", OPTION_REPLACE, one,  1, nil, []string{"REPLACE INTO `user`(`id`,`name`) VALUES(?,?)"}, false},
+        {"sqlite", OPTION_IGNORE,  one,  1, nil, []string{"INSERT OR IGNORE INTO `user`(`id`,`name`) VALUES(?,?)"}, false},
+        {"sqlite", OPTION_SAVE,    one,  1, nil, []string{"INSERT INTO `user`(`id`,`name`) VALUES(?,?) ON CONFLICT (`id`) DO UPDATE SET `name`=EXCLUDED.`name`"}, false},
+        {"sqlite", OPTION_SAVE,    list, 2, nil, []string{
+            "INSERT INTO `user`(`id`,`name`) VALUES(?,?),(?,?) ON CONFLICT (`id`) DO UPDATE SET `name`=EXCLUDED.`name`",
+            "INSERT INTO `user`(`id`,`name`) VALUES(?,?) ON CONFLICT (`id`) DO UPDATE SET `name`=EXCLUDED.`name`",
+        }, false},
+        // mssql
+        {"mssql", OPTION_INSERT, list, 10, nil, []string{"INSERT INTO [user]([id],[name]) VALUES(?,?),(?,?),(?,?)"}, false},
+        {"mssql", OPTION_SAVE,   one,  1,  nil, nil, true},
+        // oracle
+        {"oracle", OPTION_INSERT, one,  1,  nil, []string{`INSERT INTO "USER"("ID","NAME") VALUES(?,?)`}, false},
+        {"oracle", OPTION_INSERT, list, 2,  nil, []string{
+            `INSERT ALL INTO "USER"("ID","NAME") VALUES(?,?) INTO "USER"("ID","NAME") VALUES(?,?) SELECT 1 FROM DUAL`,
+            `INSERT INTO "USER"("ID","NAME") VALUES(?,?)`,
+        }, false},
+        {"oracle", OPTION_REPLACE, one, 1,  nil, nil, true},
+    }
+    for i, test := range tests {
+        sqls, err := newBuilderTestDb(test.dbType).buildInsertSqls("user", test.list, test.batch, test.option, test.conflict)
+        if test.err {
+            if err == nil {
+                t.Errorf("%d %s: expect error, got nil", i, test.dbType)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("%d %s: %v", i, test.dbType, err)
+            continue
+        }
+        if len(sqls) != len(test.sqls) {
+            t.Errorf("%d %s: expect %d sqls, got %d", i, test.dbType, len(test.sqls), len(sqls))
+            continue
+        }
+        args := 0
+        for j, v := range sqls {
+            if v.sql != test.sqls[j] {
+                t.Errorf("%d %s:\nexpect: %s\ngot:    %s", i, test.dbType, test.sqls[j], v.sql)
+            }
+            args += len(v.args)
+        }
+        if args != len(test.list) * 2 {
+            t.Errorf("%d %s: expect %d args, got %d", i, test.dbType, len(test.list) * 2, args)
+        }
+    }
+}
+
+func Test_BuildInsertSqlsWithoutConflict(t *testing.T) {
+    db := newBuilderTestDb("pgsql")
+    db.keys.Set("user", []string{})
+    if _, err := db.buildInsertSqls("user", List{{"id" : 1}}, 1, OPTION_SAVE, nil); err == nil {
+        t.Error("expect error for upsert without conflict keys")
+    }
+}
+
+func Test_BuildInsertSqlsWithoutInsertDriver(t *testing.T) {
+    Register("builder-test", &builderTestDriver{})
+    defer drivers.Remove("builder-test")
+    db   := newBuilderTestDb("builder-test")
+    list := List{{"id" : 1, "name" : "john"}, {"id" : 2, "name" : "smith"}}
+    sqls, err := db.buildInsertSqls("user", list, 10, OPTION_INSERT, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if s := "INSERT INTO `user`(`id`,`name`) VALUES(?,?),(?,?)"; len(sqls) != 1 || sqls[0].sql != s {
+        t.Errorf("expect: %s\ngot:    %v", s, sqls)
+    }
+    for _, option := range []uint8{OPTION_REPLACE, OPTION_SAVE, OPTION_IGNORE} {
+        if _, err := db.buildInsertSqls("user", list, 10, option, nil); err == nil {
+            t.Errorf("expect error for option %d", option)
+        }
+    }
+}
+
+func Test_ModelLimit(t *testing.T) {
+    tests := []struct {
+        dbType string
+        order  string
+        sql    string
+    } {
+        {"mysql",  "",   "SELECT * FROM user WHERE uid>? LIMIT 20,10"},
+        {"pgsql",  "",   "SELECT * FROM user WHERE uid>? LIMIT 10 OFFSET 20"},
+        {"sqlite", "",   "SELECT * FROM user WHERE uid>? LIMIT 10 OFFSET 20"},
+        {"mssql",  "",   "SELECT * FROM user WHERE uid>? ORDER BY (SELECT NULL) OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"},
+        {"mssql",  "id", "SELECT * FROM user WHERE uid>? ORDER BY id OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"},
+        {"oracle", "id", "SELECT * FROM user WHERE uid>? ORDER BY id OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"},
+    }
+    for _, test := range tests {
+        md := newBuilderTestDb(test.dbType).Table("user").Where("uid>?", 1).OrderBy(test.order).ForPage(3, 10)
+        if s := md.getFormattedSql(); s != test.sql {
+            t.Errorf("%s:\nexpect: %s\ngot:    %s", test.dbType, test.sql, s)
+        }
+    }
+}
+
+func Test_HandleSqlBeforeExec(t *testing.T) {
+    query := "SELECT * FROM user WHERE uid=? AND name='a?b' AND nick=?"
+    tests := []struct {
+        dbType string
+        sql    string
+    } {
+        {"mysql",  "SELECT * FROM user WHERE uid=? AND name='a?b' AND nick=?"},
+        {"pgsql",  "SELECT * FROM user WHERE uid=$1 AND name='a?b' AND nick=$2"},
+        {"sqlite", "SELECT * FROM user WHERE uid=? AND name='a?b' AND nick=?"},
+        {"mssql",  "SELECT * FROM user WHERE uid=@p1 AND name='a?b' AND nick=@p2"},
+        {"oracle", "SELECT * FROM user WHERE uid=:1 AND name='a?b' AND nick=:2"},
+    }
+    for _, test := range tests {
+        q := query
+        if s := *newBuilderTestDb(test.dbType).driver.HandleSqlBeforeExec(&q); s != test.sql {
+            t.Errorf("%s:\nexpect: %s\ngot:    %s", test.dbType, test.sql, s)
+        }
+    }
+}
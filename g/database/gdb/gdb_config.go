@@ -35,7 +35,7 @@ type ConfigNode  struct {
     User             string   // 账号
     Pass             string   // 密码
     Name             string   // 数据库名称
-    Type             string   // 数据库类型：mysql, sqlite, mssql, pgsql, oracle(目前仅支持mysql)
+    Type             string   // 数据库类型：mysql, sqlite, mssql, pgsql, oracle，或者通过Register注册的数据库类型
     Role             string   // (可选，默认为master)数据库的角色，用于主从操作分离，至少需要有一个master，参数值：master, slave
     Charset          string   // (可选，默认为 utf8)编码，默认为 utf8
     Priority         int      // (可选)用于负载均衡的权重计算，当集群中只有一个节点时，权重没有任何意义
@@ -0,0 +1,103 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 数据库驱动注册.
+
+package gdb
+
+import (
+    "fmt"
+    "strings"
+    "database/sql"
+    "gitee.com/johng/gf/g/container/gmap"
+)
+
+// 数据库驱动接口，不同类型的数据库通过实现该接口处理各自的SQL方言，
+// 第三方数据库驱动实现该接口后通过Register注册，即可在ConfigNode.Type中使用。
+type Driver interface {
+    // 打开数据库连接，建立数据库操作对象(内部一般采用lazy link处理)
+    Open(c *ConfigNode) (*sql.DB, error)
+
+    // 获得关键字操作符 - 左
+    GetQuoteCharLeft() string
+
+    // 获得关键字操作符 - 右
+    GetQuoteCharRight() string
+
+    // 在执行sql之前对sql进行进一步处理，如将?占位符转换为数据库对应的占位符
+    HandleSqlBeforeExec(q *string) *string
+
+    // 为查询SQL增加分页语句，start为起始位置(从0开始)，limit为返回条数
+    HandleLimit(q string, start, limit int) string
+}
+
+// 可选的驱动接口，用于生成Replace/Save/InsertIgnore等写入SQL，
+// 驱动没有实现该接口时只支持Insert操作(标准的INSERT INTO语句)，其他写入操作返回错误。
+type InsertDriver interface {
+    // 生成写入SQL，option为OPTION_INSERT/OPTION_REPLACE/OPTION_SAVE/OPTION_IGNORE，
+    // table/keys/conflict均已转义，values为每条记录的占位符，如：(?,?)，conflict为Save操作判断冲突的字段(可能为空)
+    HandleInsert(table string, keys []string, values []string, option uint8, conflict []string) (string, error)
+}
+
+// 可选的驱动接口，用于在转义前对表名及字段名进行处理，
+// 如Oracle未加引号的标识符按照大写存储，转义前需要转换为大写，否则无法匹配通常方式创建的数据表
+type quoteWordHandler interface {
+    HandleQuoteWord(word string) string
+}
+
+// 已注册的数据库驱动，键名为数据库类型(ConfigNode.Type)
+var drivers = gmap.NewStringInterfaceMap()
+
+// 内置数据库驱动
+func init() {
+    Register("mysql",  &dbmysql{})
+    Register("pgsql",  &dbpgsql{})
+    Register("sqlite", &dbsqlite{})
+    Register("mssql",  &dbmssql{})
+    Register("oracle", &dboracle{})
+}
+
+// 注册数据库驱动，dbType为ConfigNode.Type中使用的数据库类型名称，重复注册时覆盖原有驱动(包括内置驱动)
+func Register(dbType string, driver Driver) {
+    drivers.Set(dbType, driver)
+}
+
+// 获取数据库类型对应的驱动
+func getDriver(dbType string) (Driver, error) {
+    if v := drivers.Get(dbType); v != nil {
+        return v.(Driver), nil
+    }
+    return nil, fmt.Errorf("unsupported db type '%s'", dbType)
+}
+
+// 将SQL中的?占位符按照顺序替换为数据库对应的占位符(引号中的?不做替换)，
+// format为占位符生成方法，参数为占位符序号(从1开始)
+func replacePlaceholder(q string, format func(index int) string) string {
+    index  := 0
+    quote  := byte(0)
+    buffer := strings.Builder{}
+    buffer.Grow(len(q))
+    for i := 0; i < len(q); i++ {
+        c := q[i]
+        switch {
+            case quote != 0:
+                if c == '\\' && i + 1 < len(q) {
+                    buffer.WriteByte(c)
+                    i++
+                    c = q[i]
+                } else if c == quote {
+                    quote = 0
+                }
+            case c == '\'' || c == '"' || c == '`':
+                quote = c
+            case c == '?':
+                index++
+                buffer.WriteString(format(index))
+                continue
+        }
+        buffer.WriteByte(c)
+    }
+    return buffer.String()
+}
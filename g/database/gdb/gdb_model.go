@@ -9,6 +9,7 @@ package gdb
 import (
 	"fmt"
 	"errors"
+	"reflect"
 	"database/sql"
 	"gitee.com/johng/gf/g/util/gconv"
 	_ "github.com/go-sql-driver/mysql"
@@ -28,6 +29,9 @@ type Model struct {
 	limit        int           // 分页条数
 	data         interface{}   // 操作记录(支持Map/List/string类型)
 	batch        int           // 批量操作条数
+	conflict     []string      // Save操作判断冲突的字段(PostgreSQL/SQLite)
+	master       bool          // 查询操作是否强制使用master节点
+	err          error         // 链式操作过程中产生的错误(如Data参数转换失败)，在执行操作时返回
 	cacheEnabled bool          // 当前SQL操作是否开启查询缓存功能
 	cacheTime    int           // 查询缓存时间
 	cacheName    string        // 查询缓存名称
@@ -133,7 +137,8 @@ func (md *Model) ForPage(page, limit int) (*Model) {
 	return md
 }
 
-// 链式操作，操作数据记录项，可以是string/Map, 也可以是：key,value,key,value,...
+// 链式操作，操作数据记录项，可以是string/Map/List, 也可以是：key,value,key,value,...，
+// 也可以是struct对象(或者struct对象数组)，按照orm tag映射为数据表字段，orm:"primary"的字段作为Save的冲突字段
 func (md *Model) Data(data ...interface{}) (*Model) {
 	if len(data) > 1 {
 		m := make(map[string]interface{})
@@ -141,6 +146,30 @@ func (md *Model) Data(data ...interface{}) (*Model) {
 			m[gconv.String(data[i])] = data[i+1]
 		}
 		md.data = m
+	} else if isOrmStruct(data[0]) {
+		m, primary, err := structToMap(data[0])
+		if err != nil {
+			md.err = err
+			return md
+		}
+		md.data = m
+		if len(md.conflict) == 0 {
+			md.conflict = primary
+		}
+	} else if v := reflect.ValueOf(data[0]); v.Kind() == reflect.Slice && v.Len() > 0 && isOrmStruct(v.Index(0).Interface()) {
+		list := make(List, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			m, primary, err := structToMap(v.Index(i).Interface())
+			if err != nil {
+				md.err = err
+				return md
+			}
+			list[i] = m
+			if len(md.conflict) == 0 {
+				md.conflict = primary
+			}
+		}
+		md.data = list
 	} else {
 		md.data = data[0]
 	}
@@ -149,95 +178,59 @@ func (md *Model) Data(data ...interface{}) (*Model) {
 
 // 链式操作， CURD - Insert/BatchInsert
 func (md *Model) Insert() (result sql.Result, err error) {
-	defer func() {
-		if err == nil {
-			md.checkAndRemoveCache()
-		}
-	}()
-	if md.data == nil {
-		return nil, errors.New("inserting into table with empty data")
-	}
-	// 批量操作
-	if list, ok := md.data.(List); ok {
-		batch := 10
-		if md.batch > 0 {
-			batch = md.batch
-		}
-		if md.tx == nil {
-			return md.db.BatchInsert(md.tables, list, batch)
-		} else {
-			return md.tx.BatchInsert(md.tables, list, batch)
-		}
-	} else if dataMap, ok := md.data.(Map); ok {
-		if md.tx == nil {
-			return md.db.Insert(md.tables, dataMap)
-		} else {
-			return md.tx.Insert(md.tables, dataMap)
-		}
-	}
-	return nil, errors.New("inserting into table with invalid data type")
+	return md.doInsert(OPTION_INSERT, "inserting into")
 }
 
-// 链式操作， CURD - Replace/BatchReplace
+// 链式操作， CURD - Replace/BatchReplace，如果数据存在(主键或者唯一索引)，那么删除后重新写入一条，
+// 只有MySQL及SQLite支持，其他数据库返回错误(PostgreSQL没有删除后重新写入的语义，需要更新时请使用Save)
 func (md *Model) Replace() (result sql.Result, err error) {
-	defer func() {
-		if err == nil {
-			md.checkAndRemoveCache()
-		}
-	}()
-	if md.data == nil {
-		return nil, errors.New("replacing into table with empty data")
-	}
-	// 批量操作
-	if list, ok := md.data.(List); ok {
-		batch := 10
-		if md.batch > 0 {
-			batch = md.batch
-		}
-		if md.tx == nil {
-			return md.db.BatchReplace(md.tables, list, batch)
-		} else {
-			return md.tx.BatchReplace(md.tables, list, batch)
-		}
-	} else if dataMap, ok := md.data.(Map); ok {
-		if md.tx == nil {
-			return md.db.Insert(md.tables, dataMap)
-		} else {
-			return md.tx.Insert(md.tables, dataMap)
-		}
-	}
-	return nil, errors.New("replacing into table with invalid data type")
+	return md.doInsert(OPTION_REPLACE, "replacing into")
 }
 
 // 链式操作， CURD - Save/BatchSave
 func (md *Model) Save() (result sql.Result, err error) {
+	return md.doInsert(OPTION_SAVE, "saving into")
+}
+
+// 设置Save操作判断冲突的字段(唯一索引字段)，用于PostgreSQL/SQLite的ON CONFLICT语句，
+// 不设置时使用数据表主键，MySQL会自动根据主键及唯一索引判断冲突，不需要设置
+func (md *Model) OnConflict(fields ...string) *Model {
+	md.conflict = fields
+	return md
+}
+
+// 执行Insert/Replace/Save操作，action用于错误信息
+func (md *Model) doInsert(option uint8, action string) (result sql.Result, err error) {
 	defer func() {
 		if err == nil {
 			md.checkAndRemoveCache()
 		}
 	}()
-	if md.data == nil {
-		return nil, errors.New("replacing into table with empty data")
+	if md.err != nil {
+		return nil, md.err
 	}
-	// 批量操作
-	if list, ok := md.data.(List); ok {
-		batch := 10
+	if md.data == nil {
+		return nil, errors.New(action + " table with empty data")
+	}
+	var list List
+	batch := 1
+	if v, ok := md.data.(List); ok {
+		// 批量操作
+		list  = v
+		batch = 10
 		if md.batch > 0 {
 			batch = md.batch
 		}
-		if md.tx == nil {
-			return md.db.BatchSave(md.tables, list, batch)
-		} else {
-			return md.tx.BatchSave(md.tables, list, batch)
-		}
-	} else if dataMap, ok := md.data.(Map); ok {
-		if md.tx == nil {
-			return md.db.Save(md.tables, dataMap)
-		} else {
-			return md.tx.Save(md.tables, dataMap)
-		}
+	} else if v, ok := md.data.(Map); ok {
+		list = List{v}
+	} else {
+		return nil, errors.New(action + " table with invalid data type")
+	}
+	if md.tx == nil {
+		return md.db.batchInsert(md.tables, list, batch, option, md.conflict...)
+	} else {
+		return md.tx.batchInsert(md.tables, list, batch, option, md.conflict...)
 	}
-	return nil, errors.New("saving into table with invalid data type")
 }
 
 // 链式操作， CURD - Update
@@ -247,6 +240,9 @@ func (md *Model) Update() (result sql.Result, err error) {
 			md.checkAndRemoveCache()
 		}
 	}()
+	if md.err != nil {
+		return nil, md.err
+	}
 	if md.data == nil {
 		return nil, errors.New("updating table with empty data")
 	}
@@ -296,6 +292,12 @@ func (md *Model) Cache(time int, name ... string) *Model {
 	return md
 }
 
+// 查询操作强制使用master节点(如写入后需要立即读取，避免主从同步延迟)，事务操作本身即使用master节点
+func (md *Model) Master() *Model {
+	md.master = true
+	return md
+}
+
 // 链式操作，select
 func (md *Model) Select() (Result, error) {
 	return md.getAll(md.getFormattedSql(), md.whereArgs...)
@@ -339,6 +341,15 @@ func (md *Model) Struct(obj interface{}) error {
 	return one.ToStruct(obj)
 }
 
+// 链式操作，查询多条记录，并自动转换为struct数组，objs为数组指针，如：&[]User或者&[]*User
+func (md *Model) Structs(objs interface{}) error {
+	list, err := md.All()
+	if err != nil {
+		return err
+	}
+	return list.ToStructs(objs)
+}
+
 // 链式操作，查询数量，fields可以为空，也可以自定义查询字段，
 // 当给定自定义查询字段时，该字段必须为数量结果，否则会引起歧义，使用如：md.Fields("COUNT(id)")
 func (md *Model) Count() (int, error) {
@@ -375,7 +386,11 @@ func (md *Model) getAll(sql string, args ...interface{}) (result Result, err err
 		}
 	}
 	if md.tx == nil {
-		result, err = md.db.GetAll(sql, args...)
+		if md.master {
+			result, err = md.db.doGetAll(md.db.master, sql, args...)
+		} else {
+			result, err = md.db.GetAll(sql, args...)
+		}
 	} else {
 		result, err = md.tx.GetAll(sql, args...)
 	}
@@ -413,7 +428,7 @@ func (md *Model) getFormattedSql() string {
 		s += " ORDER BY " + md.orderBy
 	}
 	if md.limit != 0 {
-		s += fmt.Sprintf(" LIMIT %d, %d", md.start, md.limit)
+		s = md.db.driver.HandleLimit(s, md.start, md.limit)
 	}
 	return s
 }
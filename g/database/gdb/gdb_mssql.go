@@ -0,0 +1,86 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+package gdb
+
+import (
+    "fmt"
+    "regexp"
+    "net/url"
+    "database/sql"
+)
+
+// SQL Server的适配(分页语法需要SQL Server 2012及以上版本).
+// 使用时需要import:
+// _ "github.com/denisenkom/go-mssqldb"
+
+// 数据库链接对象
+type dbmssql struct {}
+
+// 没有配置端口时使用的默认端口
+const gMSSQL_DEFAULT_PORT = "1433"
+
+// 判断查询SQL中是否已有ORDER BY语句(OFFSET分页必须带有ORDER BY)
+var mssqlOrderByRegex = regexp.MustCompile(`(?i)\sORDER\s+BY\s`)
+
+// 创建SQL操作对象，内部采用了lazy link处理
+func (db *dbmssql) Open (c *ConfigNode) (*sql.DB, error) {
+    var source string
+    if c.Linkinfo != "" {
+        source = c.Linkinfo
+    } else {
+        port := c.Port
+        if port == "" {
+            port = gMSSQL_DEFAULT_PORT
+        }
+        u := url.URL {
+            Scheme   : "sqlserver",
+            User     : url.UserPassword(c.User, c.Pass),
+            Host     : fmt.Sprintf("%s:%s", c.Host, port),
+            RawQuery : url.Values{"database" : {c.Name}}.Encode(),
+        }
+        source = u.String()
+    }
+    if db, err := sql.Open("sqlserver", source); err == nil {
+        return db, nil
+    } else {
+        return nil, err
+    }
+}
+
+// 获得关键字操作符 - 左
+func (db *dbmssql) GetQuoteCharLeft () string {
+    return "["
+}
+
+// 获得关键字操作符 - 右
+func (db *dbmssql) GetQuoteCharRight () string {
+    return "]"
+}
+
+// 在执行sql之前对sql进行进一步处理，将?占位符转换为@p1, @p2...
+func (db *dbmssql) HandleSqlBeforeExec(q *string) *string {
+    str := replacePlaceholder(*q, func(index int) string {
+        return fmt.Sprintf("@p%d", index)
+    })
+    return &str
+}
+
+// 为查询SQL增加分页语句，没有ORDER BY时使用ORDER BY (SELECT NULL)
+func (db *dbmssql) HandleLimit (q string, start, limit int) string {
+    if !mssqlOrderByRegex.MatchString(q + " ") {
+        q += " ORDER BY (SELECT NULL)"
+    }
+    return fmt.Sprintf("%s OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", q, start, limit)
+}
+
+// 生成写入SQL，目前仅支持Insert操作
+func (db *dbmssql) HandleInsert (table string, keys []string, values []string, option uint8, conflict []string) (string, error) {
+    if option != OPTION_INSERT {
+        return "", unsupportedInsertOption("mssql", option)
+    }
+    return buildInsertSql("INSERT", table, keys, values, ""), nil
+}
@@ -9,13 +9,12 @@ package gdb
 
 import (
     "fmt"
+    "strings"
     "database/sql"
 )
 
 // 数据库链接对象
-type dbmysql struct {
-    Db
-}
+type dbmysql struct {}
 
 // 创建SQL操作对象，内部采用了lazy link处理
 func (db *dbmysql) Open (c *ConfigNode) (*sql.DB, error) {
@@ -33,16 +32,38 @@ func (db *dbmysql) Open (c *ConfigNode) (*sql.DB, error) {
 }
 
 // 获得关键字操作符 - 左
-func (db *dbmysql) getQuoteCharLeft () string {
+func (db *dbmysql) GetQuoteCharLeft () string {
     return "`"
 }
 
 // 获得关键字操作符 - 右
-func (db *dbmysql) getQuoteCharRight () string {
+func (db *dbmysql) GetQuoteCharRight () string {
     return "`"
 }
 
 // 在执行sql之前对sql进行进一步处理
-func (db *dbmysql) handleSqlBeforeExec(q *string) *string {
+func (db *dbmysql) HandleSqlBeforeExec(q *string) *string {
     return q
-}
\ No newline at end of file
+}
+
+// 为查询SQL增加分页语句
+func (db *dbmysql) HandleLimit (q string, start, limit int) string {
+    return fmt.Sprintf("%s LIMIT %d,%d", q, start, limit)
+}
+
+// 生成写入SQL
+func (db *dbmysql) HandleInsert (table string, keys []string, values []string, option uint8, conflict []string) (string, error) {
+    switch option {
+        case OPTION_REPLACE:
+            return buildInsertSql("REPLACE", table, keys, values, ""), nil
+        case OPTION_IGNORE:
+            return buildInsertSql("INSERT IGNORE", table, keys, values, ""), nil
+        case OPTION_SAVE:
+            updates := make([]string, len(keys))
+            for i, k := range keys {
+                updates[i] = fmt.Sprintf("%s=VALUES(%s)", k, k)
+            }
+            return buildInsertSql("INSERT", table, keys, values, " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ",")), nil
+    }
+    return buildInsertSql("INSERT", table, keys, values, ""), nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+package gdb
+
+import (
+    "fmt"
+    "strings"
+    "database/sql"
+)
+
+// Oracle的适配(分页语法需要Oracle 12c及以上版本).
+// 使用时需要import:
+// _ "github.com/mattn/go-oci8"
+
+// 数据库链接对象
+type dboracle struct {}
+
+// 创建SQL操作对象，内部采用了lazy link处理
+func (db *dboracle) Open (c *ConfigNode) (*sql.DB, error) {
+    var source string
+    if c.Linkinfo != "" {
+        source = c.Linkinfo
+    } else {
+        source = fmt.Sprintf("%s/%s@%s:%s/%s", c.User, c.Pass, c.Host, c.Port, c.Name)
+    }
+    if db, err := sql.Open("oci8", source); err == nil {
+        return db, nil
+    } else {
+        return nil, err
+    }
+}
+
+// 获得关键字操作符 - 左
+func (db *dboracle) GetQuoteCharLeft () string {
+    return "\""
+}
+
+// 获得关键字操作符 - 右
+func (db *dboracle) GetQuoteCharRight () string {
+    return "\""
+}
+
+// 转义前将表名及字段名转换为大写，与未加引号创建的数据表(Oracle按照大写存储)保持一致，
+// 同时可以避免与保留字(如USER、LEVEL)冲突
+func (db *dboracle) HandleQuoteWord(word string) string {
+    return strings.ToUpper(word)
+}
+
+// 在执行sql之前对sql进行进一步处理，将?占位符转换为:1, :2...
+func (db *dboracle) HandleSqlBeforeExec(q *string) *string {
+    str := replacePlaceholder(*q, func(index int) string {
+        return fmt.Sprintf(":%d", index)
+    })
+    return &str
+}
+
+// 为查询SQL增加分页语句
+func (db *dboracle) HandleLimit (q string, start, limit int) string {
+    return fmt.Sprintf("%s OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", q, start, limit)
+}
+
+// 生成写入SQL，目前仅支持Insert操作，Oracle不支持多行VALUES，批量写入使用INSERT ALL处理
+func (db *dboracle) HandleInsert (table string, keys []string, values []string, option uint8, conflict []string) (string, error) {
+    if option != OPTION_INSERT {
+        return "", unsupportedInsertOption("oracle", option)
+    }
+    if len(values) == 1 {
+        return buildInsertSql("INSERT", table, keys, values, ""), nil
+    }
+    into := fmt.Sprintf(" INTO %s(%s) VALUES", table, strings.Join(keys, ","))
+    return "INSERT ALL" + into + strings.Join(values, into) + " SELECT 1 FROM DUAL", nil
+}
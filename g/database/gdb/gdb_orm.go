@@ -0,0 +1,443 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// struct对象与数据表记录的映射(orm tag).
+
+package gdb
+
+import (
+    "time"
+    "errors"
+    "strings"
+    "reflect"
+    "database/sql"
+    "encoding/json"
+    "database/sql/driver"
+    "gitee.com/johng/gf/g/util/gconv"
+    "gitee.com/johng/gf/g/container/gmap"
+)
+
+// 写入时time.Time类型属性的格式
+const gORM_TIME_FORMAT = "2006-01-02 15:04:05"
+
+// struct属性与数据表字段的映射信息，按照struct类型缓存
+type ormStruct struct {
+    fields  []*ormField          // 所有映射的属性(按照定义顺序，嵌套struct的属性展开)
+    columns map[string]*ormField // 字段名称 => 属性
+    loose   map[string]*ormField // 宽松匹配的字段名称(小写且去掉下划线) => 属性
+}
+
+// struct属性映射信息
+type ormField struct {
+    index     []int  // 属性索引(包含嵌套struct的索引路径)
+    column    string // 数据表字段名称
+    primary   bool   // 是否主键
+    omitempty bool   // 写入时是否忽略零值
+}
+
+var (
+    // struct映射信息缓存，键名为reflect.Type
+    ormStructs = gmap.NewInterfaceInterfaceMap()
+    // 常用类型
+    timeType     = reflect.TypeOf(time.Time{})
+    nullTimeType = reflect.TypeOf(sql.NullTime{})
+    scannerType  = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+    valuerType   = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// struct映射写入NULL时使用的值。只有struct映射的nil指针、nil slice/map及无效的sql.Null*写入NULL，
+// Map数据中的nil值与之前的版本保持一致，按照空字符串写入(见convertParam)
+type ormNullValue struct{}
+
+var ormNull = ormNullValue{}
+
+// 获取struct类型的映射信息。
+// 属性tag格式为：orm:"字段名称,primary,omitempty"，各项均可选，如：orm:"user_name"，orm:"primary"，orm:",omitempty"，
+// orm:"-"表示忽略该属性；没有orm tag时使用gconv tag，都没有时使用属性名称的蛇形命名作为字段名称(如UserName为user_name)，
+// 读取时字段名称还支持宽松匹配(忽略大小写及下划线)，写入时使用以上规则得到的字段名称，与数据表字段不一致时需要设置orm tag；
+// 匿名(嵌套)struct属性会被展开，外层属性优先。
+func getOrmStruct(t reflect.Type) *ormStruct {
+    if v := ormStructs.Get(t); v != nil {
+        return v.(*ormStruct)
+    }
+    s := &ormStruct {
+        fields  : make([]*ormField, 0),
+        columns : make(map[string]*ormField),
+        loose   : make(map[string]*ormField),
+    }
+    parseOrmFields(t, nil, s)
+    for _, f := range s.fields {
+        if _, ok := s.loose[ormLooseName(f.column)]; !ok {
+            s.loose[ormLooseName(f.column)] = f
+        }
+    }
+    ormStructs.Set(t, s)
+    return s
+}
+
+// 解析struct属性，index为嵌套struct的索引路径
+func parseOrmFields(t reflect.Type, index []int, s *ormStruct) {
+    for i := 0; i < t.NumField(); i++ {
+        field := t.Field(i)
+        tag   := field.Tag.Get("orm")
+        if tag == "-" {
+            continue
+        }
+        path := make([]int, len(index) + 1)
+        copy(path, index)
+        path[len(index)] = i
+        // 没有指定字段名称的嵌套struct，展开其属性
+        ft := field.Type
+        if ft.Kind() == reflect.Ptr {
+            ft = ft.Elem()
+        }
+        if field.Anonymous && tag == "" && ft.Kind() == reflect.Struct && !isOrmValueType(ft) {
+            // 私有类型的嵌套struct指针无法创建对象
+            if field.PkgPath != "" && field.Type.Kind() == reflect.Ptr {
+                continue
+            }
+            parseOrmFields(ft, path, s)
+            continue
+        }
+        if field.PkgPath != "" {
+            continue
+        }
+        f := &ormField {
+            index  : path,
+            column : ormSnakeName(field.Name),
+        }
+        if tag == "" {
+            if v := field.Tag.Get("gconv"); v != "" {
+                f.column = strings.TrimSpace(strings.Split(v, ",")[0])
+            }
+        }
+        for i, v := range strings.Split(tag, ",") {
+            switch v = strings.TrimSpace(v); v {
+                case "primary":   f.primary   = true
+                case "omitempty": f.omitempty = true
+                default:
+                    if i == 0 && v != "" {
+                        f.column = v
+                    }
+            }
+        }
+        // 同名字段时，嵌套层级浅的属性优先
+        if exist, ok := s.columns[f.column]; ok {
+            if len(exist.index) <= len(f.index) {
+                continue
+            }
+            for k, v := range s.fields {
+                if v == exist {
+                    s.fields = append(s.fields[:k], s.fields[k + 1:]...)
+                    break
+                }
+            }
+        }
+        s.fields            = append(s.fields, f)
+        s.columns[f.column] = f
+    }
+}
+
+// 是否为作为整体映射的类型(不展开的struct)
+func isOrmValueType(t reflect.Type) bool {
+    return t == timeType || reflect.PtrTo(t).Implements(scannerType) || t.Implements(valuerType)
+}
+
+// 属性名称转换为蛇形命名的字段名称，如：UserName为user_name，UserID为user_id，HTTPServer为http_server
+func ormSnakeName(name string) string {
+    b := make([]byte, 0, len(name) + 4)
+    for i := 0; i < len(name); i++ {
+        c := name[i]
+        if c >= 'A' && c <= 'Z' {
+            if i > 0 {
+                prev  := name[i - 1]
+                lower := (prev >= 'a' && prev <= 'z') || (prev >= '0' && prev <= '9')
+                // 连续大写字母(缩写)的最后一个字母后跟小写字母时为新单词的开始
+                if lower || (prev >= 'A' && prev <= 'Z' && i + 1 < len(name) && name[i + 1] >= 'a' && name[i + 1] <= 'z') {
+                    b = append(b, '_')
+                }
+            }
+            c += 'a' - 'A'
+        }
+        b = append(b, c)
+    }
+    return string(b)
+}
+
+// 宽松匹配的字段名称，如：user_name与UserName均为username
+func ormLooseName(name string) string {
+    return strings.ToLower(strings.Replace(name, "_", "", -1))
+}
+
+// 根据字段名称查找属性，优先精确匹配，其次宽松匹配
+func (s *ormStruct) lookup(column string) *ormField {
+    if f, ok := s.columns[column]; ok {
+        return f
+    }
+    return s.loose[ormLooseName(column)]
+}
+
+// 结果集字段与属性的绑定关系
+type ormBinding struct {
+    column string
+    field  *ormField
+}
+
+// 根据记录的字段名称生成绑定关系，同一结果集的记录字段相同，只需要生成一次
+func (s *ormStruct) bindings(r Record) []ormBinding {
+    bindings := make([]ormBinding, 0, len(r))
+    for column, _ := range r {
+        if f := s.lookup(column); f != nil {
+            bindings = append(bindings, ormBinding{column, f})
+        }
+    }
+    return bindings
+}
+
+// 按照绑定关系将记录映射到struct对象(reflect.Value必须为可设置的struct)
+func bindOrmValues(bindings []ormBinding, r Record, elem reflect.Value) error {
+    for _, b := range bindings {
+        if err := setOrmValue(ormFieldByIndex(elem, b.field.index, true), r[b.column]); err != nil {
+            return errors.New("cannot bind column '" + b.column + "': " + err.Error())
+        }
+    }
+    return nil
+}
+
+// 按照索引路径获取属性，alloc为true时为nil的嵌套struct指针创建对象，否则返回无效的reflect.Value
+func ormFieldByIndex(v reflect.Value, index []int, alloc bool) reflect.Value {
+    for i, x := range index {
+        if i > 0 && v.Kind() == reflect.Ptr {
+            if v.IsNil() {
+                if !alloc {
+                    return reflect.Value{}
+                }
+                v.Set(reflect.New(v.Type().Elem()))
+            }
+            v = v.Elem()
+        }
+        v = v.Field(x)
+    }
+    return v
+}
+
+// 将字段值设置到属性，字段值为nil(NULL)时设置为零值，支持指针、sql.Scanner(如sql.NullString)及time.Time类型属性
+func setOrmValue(f reflect.Value, v Value) error {
+    if f.CanAddr() && f.Addr().Type().Implements(scannerType) {
+        scanner := f.Addr().Interface().(sql.Scanner)
+        if v == nil {
+            return scanner.Scan(nil)
+        }
+        // 查询结果的字段值为[]byte，sql.NullTime不支持从[]byte转换，需要先解析为time.Time
+        if f.Type() == nullTimeType {
+            t := v.Time()
+            if t.IsZero() {
+                return errors.New("cannot parse '" + v.String() + "' as time")
+            }
+            return scanner.Scan(t)
+        }
+        if err := scanner.Scan([]byte(v)); err != nil {
+            // 其他包装time.Time的类型(如第三方驱动的NullTime)，解析为time.Time后再尝试
+            if t := v.Time(); !t.IsZero() && scanner.Scan(t) == nil {
+                return nil
+            }
+            return err
+        }
+        return nil
+    }
+    if f.Kind() == reflect.Ptr {
+        if v == nil {
+            f.Set(reflect.Zero(f.Type()))
+            return nil
+        }
+        p := reflect.New(f.Type().Elem())
+        if err := setOrmValue(p.Elem(), v); err != nil {
+            return err
+        }
+        f.Set(p)
+        return nil
+    }
+    if v == nil {
+        f.Set(reflect.Zero(f.Type()))
+        return nil
+    }
+    if f.Type() == timeType {
+        f.Set(reflect.ValueOf(v.Time()))
+        return nil
+    }
+    switch f.Kind() {
+        case reflect.String:
+            f.SetString(v.String())
+        case reflect.Bool:
+            f.SetBool(v.Bool())
+        case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+            f.SetInt(v.Int64())
+        case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+            f.SetUint(v.Uint64())
+        case reflect.Float32, reflect.Float64:
+            f.SetFloat(v.Float64())
+        case reflect.Slice:
+            if f.Type().Elem().Kind() == reflect.Uint8 {
+                f.SetBytes(append([]byte(nil), v...))
+                return nil
+            }
+            fallthrough
+        case reflect.Map, reflect.Struct, reflect.Array:
+            // 复杂类型按照JSON格式解析
+            p := reflect.New(f.Type())
+            if err := json.Unmarshal(v, p.Interface()); err != nil {
+                return err
+            }
+            f.Set(p.Elem())
+        default:
+            converted := reflect.ValueOf(gconv.Convert(v.String(), f.Type().String()))
+            if converted.IsValid() && converted.Type().AssignableTo(f.Type()) {
+                f.Set(converted)
+            }
+    }
+    return nil
+}
+
+// 将struct对象转换为写入的数据Map，同时返回主键字段列表。
+// omitempty的属性为零值时忽略，nil指针及nil slice/map写入NULL(ormNull)，driver.Valuer(如sql.NullString)使用其Value，
+// time.Time转换为日期时间字符串。
+func structToMap(obj interface{}) (Map, []string, error) {
+    v := reflect.ValueOf(obj)
+    for v.Kind() == reflect.Ptr {
+        if v.IsNil() {
+            return nil, nil, errors.New("nil struct pointer")
+        }
+        v = v.Elem()
+    }
+    if v.Kind() != reflect.Struct {
+        return nil, nil, errors.New("struct or struct pointer expected, got " + v.Kind().String())
+    }
+    s       := getOrmStruct(v.Type())
+    data    := make(Map, len(s.fields))
+    primary := make([]string, 0)
+    for _, f := range s.fields {
+        fv := ormFieldByIndex(v, f.index, false)
+        if !fv.IsValid() || (f.omitempty && fv.IsZero()) {
+            continue
+        }
+        value, err := ormWriteValue(fv)
+        if err != nil {
+            return nil, nil, err
+        }
+        if value == nil {
+            value = ormNull
+        }
+        data[f.column] = value
+        if f.primary {
+            primary = append(primary, f.column)
+        }
+    }
+    return data, primary, nil
+}
+
+// 属性值转换为写入的值
+func ormWriteValue(v reflect.Value) (interface{}, error) {
+    if v.Type().Implements(valuerType) {
+        if v.Kind() == reflect.Ptr && v.IsNil() {
+            return nil, nil
+        }
+        value, err := v.Interface().(driver.Valuer).Value()
+        if t, ok := value.(time.Time); ok {
+            return t.Format(gORM_TIME_FORMAT), err
+        }
+        return value, err
+    }
+    if v.Kind() == reflect.Ptr {
+        if v.IsNil() {
+            return nil, nil
+        }
+        return ormWriteValue(v.Elem())
+    }
+    if v.Type() == timeType {
+        return v.Interface().(time.Time).Format(gORM_TIME_FORMAT), nil
+    }
+    switch v.Kind() {
+        case reflect.Struct, reflect.Array:
+            b, err := json.Marshal(v.Interface())
+            return string(b), err
+        case reflect.Map, reflect.Slice:
+            if v.IsNil() {
+                return nil, nil
+            }
+            if v.Kind() == reflect.Map {
+                b, err := json.Marshal(v.Interface())
+                return string(b), err
+            }
+            if v.Type().Elem().Kind() == reflect.Uint8 {
+                return v.Bytes(), nil
+            }
+            b, err := json.Marshal(v.Interface())
+            return string(b), err
+    }
+    return v.Interface(), nil
+}
+
+// 判断是否为struct对象(或者struct指针)，time.Time等作为整体写入的类型除外
+func isOrmStruct(obj interface{}) bool {
+    t := reflect.TypeOf(obj)
+    for t != nil && t.Kind() == reflect.Ptr {
+        t = t.Elem()
+    }
+    return t != nil && t.Kind() == reflect.Struct && !isOrmValueType(t)
+}
+
+// 将记录映射到给定的struct对象中，注意参数应当是一个对象的指针，
+// 字段与属性的映射规则参考orm tag说明(getOrmStruct)
+func (r Record) ToStruct(obj interface{}) error {
+    v := reflect.ValueOf(obj)
+    if v.Kind() != reflect.Ptr || v.IsNil() {
+        return errors.New("object pointer expected")
+    }
+    elem := v.Elem()
+    if elem.Kind() == reflect.Ptr {
+        if elem.IsNil() {
+            elem.Set(reflect.New(elem.Type().Elem()))
+        }
+        elem = elem.Elem()
+    }
+    if elem.Kind() != reflect.Struct {
+        return errors.New("struct pointer expected, got " + elem.Kind().String())
+    }
+    return bindOrmValues(getOrmStruct(elem.Type()).bindings(r), r, elem)
+}
+
+// 将结果集映射到给定的struct数组中，参数应当为数组指针，数组元素可以为struct或者struct指针，如：&[]User或者&[]*User。
+// 字段与属性的映射关系只在第一条记录时计算一次，后续记录直接按照索引设置属性值。
+func (r Result) ToStructs(objs interface{}) error {
+    v := reflect.ValueOf(objs)
+    if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+        return errors.New("slice pointer expected")
+    }
+    sliceType := v.Elem().Type()
+    itemType  := sliceType.Elem()
+    isPtr     := itemType.Kind() == reflect.Ptr
+    if isPtr {
+        itemType = itemType.Elem()
+    }
+    if itemType.Kind() != reflect.Struct {
+        return errors.New("struct slice pointer expected, got element type " + itemType.String())
+    }
+    slice := reflect.MakeSlice(sliceType, len(r), len(r))
+    if len(r) > 0 {
+        bindings := getOrmStruct(itemType).bindings(r[0])
+        for i, record := range r {
+            item := slice.Index(i)
+            if isPtr {
+                item.Set(reflect.New(itemType))
+                item = item.Elem()
+            }
+            if err := bindOrmValues(bindings, record, item); err != nil {
+                return err
+            }
+        }
+    }
+    v.Elem().Set(slice)
+    return nil
+}
@@ -0,0 +1,245 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+// struct与数据表记录映射单元测试(不需要数据库连接)
+// go test *.go
+
+package gdb
+
+import (
+    "time"
+    "strconv"
+    "testing"
+    "database/sql"
+)
+
+type ormTestBase struct {
+    Id      int       `orm:"id,primary"`
+    Created time.Time `orm:"created_at,omitempty"`
+}
+
+type ormTestExtra struct {
+    Remark string `orm:"remark"`
+}
+
+type ormTestUser struct {
+    ormTestBase
+    *OrmTestExtraPtr
+    Extra    *ormTestExtra `orm:"-"`
+    UserName string        `orm:"user_name"`
+    Nickname *string
+    Age      sql.NullInt64 `orm:"age"`
+    Email    string        `orm:"email,omitempty"`
+    Tags     []string      `orm:"tags"`
+    LastIP   string
+    Login    sql.NullTime  `orm:"login_at"`
+    Score    float64       `gconv:"user_score"`
+    password string
+}
+
+type OrmTestExtraPtr struct {
+    Level int `orm:"level"`
+}
+
+func Test_RecordToStruct(t *testing.T) {
+    record := Record {
+        "id"         : Value("10"),
+        "created_at" : Value("2018-08-08 10:00:00"),
+        "user_name"  : Value("john"),
+        "nickname"   : nil,
+        "age"        : Value("18"),
+        "email"      : Value("john@example.com"),
+        "tags"       : Value(`["a","b"]`),
+        "user_score" : Value("99.5"),
+        "level"      : Value("3"),
+        "last_ip"    : Value("127.0.0.1"),
+        "login_at"   : Value("2018-08-08 11:00:00"),
+        "remark"     : Value("ignored"),
+        "password"   : Value("ignored"),
+    }
+    user := new(ormTestUser)
+    if err := record.ToStruct(user); err != nil {
+        t.Fatal(err)
+    }
+    if user.Id != 10 || user.UserName != "john" || user.Email != "john@example.com" || user.Score != 99.5 {
+        t.Errorf("unexpected basic fields: %+v", user)
+    }
+    if user.Created.Format("2006-01-02 15:04:05") != "2018-08-08 10:00:00" {
+        t.Errorf("unexpected time: %v", user.Created)
+    }
+    if user.Nickname != nil {
+        t.Errorf("NULL column should be mapped to nil pointer")
+    }
+    if !user.Age.Valid || user.Age.Int64 != 18 {
+        t.Errorf("unexpected sql.NullInt64: %+v", user.Age)
+    }
+    if len(user.Tags) != 2 || user.Tags[1] != "b" {
+        t.Errorf("unexpected json field: %v", user.Tags)
+    }
+    if user.LastIP != "127.0.0.1" {
+        t.Errorf("untagged field should be mapped by snake case name, got %q", user.LastIP)
+    }
+    if !user.Login.Valid || user.Login.Time.Format("2006-01-02 15:04:05") != "2018-08-08 11:00:00" {
+        t.Errorf("unexpected sql.NullTime: %+v", user.Login)
+    }
+    if user.OrmTestExtraPtr == nil || user.Level != 3 {
+        t.Errorf("embedded struct pointer should be created")
+    }
+    if user.Extra != nil || user.password != "" {
+        t.Errorf("ignored fields should not be set")
+    }
+    // NULL的sql.Null*类型
+    record["age"]      = nil
+    record["login_at"] = nil
+    if err := record.ToStruct(user); err != nil || user.Age.Valid || user.Login.Valid {
+        t.Errorf("NULL column should be mapped to invalid sql.Null*, %v", err)
+    }
+    // 无法解析的时间
+    record["login_at"] = Value("invalid")
+    if err := record.ToStruct(user); err == nil {
+        t.Errorf("invalid time should return error")
+    }
+    // 宽松匹配
+    user2 := new(struct{ UserName string })
+    if err := record.ToStruct(user2); err != nil || user2.UserName != "john" {
+        t.Errorf("user_name should be mapped to UserName, %v", err)
+    }
+}
+
+func Test_StructToMap(t *testing.T) {
+    nick := "j"
+    user := &ormTestUser{UserName : "john", Nickname : &nick, Age : sql.NullInt64{Int64 : 18, Valid : true}}
+    user.Id = 1
+    data, primary, err := structToMap(user)
+    if err != nil {
+        t.Fatal(err)
+    }
+    expect := Map {
+        "id"         : 1,
+        "user_name"  : "john",
+        "nickname"   : "j",
+        "age"        : int64(18),
+        "tags"       : ormNull,
+        "last_ip"    : "",
+        "login_at"   : ormNull,
+        "user_score" : float64(0),
+    }
+    if len(data) != len(expect) {
+        t.Errorf("expect %v, got %v", expect, data)
+    }
+    for k, v := range expect {
+        if data[k] != v {
+            t.Errorf("%s: expect %v, got %v", k, v, data[k])
+        }
+    }
+    if len(primary) != 1 || primary[0] != "id" {
+        t.Errorf("unexpected primary keys: %v", primary)
+    }
+    // nil指针及无效的sql.Null*写入NULL
+    user.Nickname = nil
+    user.Age      = sql.NullInt64{}
+    user.Tags     = []string{}
+    user.Created  = time.Date(2018, 8, 8, 10, 0, 0, 0, time.Local)
+    user.Login    = sql.NullTime{Time : user.Created, Valid : true}
+    data, _, _    = structToMap(user)
+    if v, ok := data["nickname"]; !ok || v != ormNull || convertParam(v) != nil {
+        t.Errorf("nil pointer should be written as NULL")
+    }
+    if v, ok := data["age"]; !ok || v != ormNull {
+        t.Errorf("invalid sql.NullInt64 should be written as NULL")
+    }
+    if data["tags"] != "[]" {
+        t.Errorf("empty slice should be written as json, got %v", data["tags"])
+    }
+    if data["created_at"] != "2018-08-08 10:00:00" || data["login_at"] != "2018-08-08 10:00:00" {
+        t.Errorf("unexpected time: %v, %v", data["created_at"], data["login_at"])
+    }
+    // Map数据中的nil值写入空字符串
+    if v := convertParam(nil); v != "" {
+        t.Errorf("nil value of map data should be written as empty string, got %v", v)
+    }
+}
+
+func Test_OrmSnakeName(t *testing.T) {
+    for name, expect := range map[string]string {
+        "Id"         : "id",
+        "UserName"   : "user_name",
+        "UserID"     : "user_id",
+        "HTTPServer" : "http_server",
+        "Address2"   : "address2",
+        "user_name"  : "user_name",
+    } {
+        if v := ormSnakeName(name); v != expect {
+            t.Errorf("%s: expect %s, got %s", name, expect, v)
+        }
+    }
+}
+
+func Test_ModelDataStruct(t *testing.T) {
+    db   := newBuilderTestDb("pgsql")
+    user := ormTestUser{UserName : "john"}
+    md   := db.Table("user").Data([]ormTestUser{user, user})
+    if list, ok := md.data.(List); !ok || len(list) != 2 {
+        t.Fatalf("struct slice should be converted to List, got %T", md.data)
+    }
+    if len(md.conflict) != 1 || md.conflict[0] != "id" {
+        t.Errorf("primary fields should be used as conflict keys, got %v", md.conflict)
+    }
+    if _, err := db.Table("user").Data((*ormTestUser)(nil)).Save(); err == nil {
+        t.Errorf("nil struct pointer should return error")
+    }
+}
+
+func newOrmTestResult(n int) Result {
+    r := make(Result, n)
+    for i := 0; i < n; i++ {
+        r[i] = Record {
+            "id"        : Value(strconv.Itoa(i + 1)),
+            "user_name" : Value("john"),
+            "age"       : Value("18"),
+            "level"     : Value("3"),
+            "unknown"   : Value("ignored"),
+        }
+    }
+    return r
+}
+
+func Test_ResultToStructs(t *testing.T) {
+    result := newOrmTestResult(3)
+    users  := make([]ormTestUser, 0)
+    if err := result.ToStructs(&users); err != nil {
+        t.Fatal(err)
+    }
+    if len(users) != 3 || users[2].Id != 3 || users[0].UserName != "john" || users[1].Level != 3 {
+        t.Errorf("unexpected structs: %+v", users)
+    }
+    pointers := make([]*ormTestUser, 0)
+    if err := result.ToStructs(&pointers); err != nil {
+        t.Fatal(err)
+    }
+    if len(pointers) != 3 || pointers[2].Id != 3 || !pointers[0].Age.Valid {
+        t.Errorf("unexpected struct pointers: %+v", pointers)
+    }
+    // 空结果集
+    if err := Result(nil).ToStructs(&pointers); err != nil || len(pointers) != 0 {
+        t.Errorf("empty result should clear slice, %v", err)
+    }
+    // 错误参数
+    for _, v := range []interface{}{users, &result, new([]int), nil} {
+        if err := result.ToStructs(v); err == nil {
+            t.Errorf("expect error for %T", v)
+        }
+    }
+}
+
+func Benchmark_ResultToStructs(b *testing.B) {
+    result := newOrmTestResult(1000)
+    users  := make([]*ormTestUser, 0)
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        result.ToStructs(&users)
+    }
+}
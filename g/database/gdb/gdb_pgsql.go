@@ -9,19 +9,15 @@ package gdb
 
 import (
     "fmt"
-    "regexp"
     "database/sql"
 )
 
 // PostgreSQL的适配.
 // 使用时需要import:
 // _ "github.com/lib/pq"
-// @todo 需要完善replace和save的操作覆盖
 
 // 数据库链接对象
-type dbpgsql struct {
-    Db
-}
+type dbpgsql struct {}
 
 // 创建SQL操作对象，内部采用了lazy link处理
 func (db *dbpgsql) Open (c *ConfigNode) (*sql.DB, error) {
@@ -39,23 +35,62 @@ func (db *dbpgsql) Open (c *ConfigNode) (*sql.DB, error) {
 }
 
 // 获得关键字操作符 - 左
-func (db *dbpgsql) getQuoteCharLeft () string {
+func (db *dbpgsql) GetQuoteCharLeft () string {
     return "\""
 }
 
 // 获得关键字操作符 - 右
-func (db *dbpgsql) getQuoteCharRight () string {
+func (db *dbpgsql) GetQuoteCharRight () string {
     return "\""
 }
 
 // 在执行sql之前对sql进行进一步处理
-func (db *dbpgsql) handleSqlBeforeExec(q *string) *string {
-    reg   := regexp.MustCompile("\\?")
-    index := 0
-    str   := reg.ReplaceAllStringFunc(*q, func (s string) string {
-        index ++
+func (db *dbpgsql) HandleSqlBeforeExec(q *string) *string {
+    str := replacePlaceholder(*q, func(index int) string {
         return fmt.Sprintf("$%d", index)
     })
     return &str
 }
 
+// 为查询SQL增加分页语句
+func (db *dbpgsql) HandleLimit (q string, start, limit int) string {
+    return fmt.Sprintf("%s LIMIT %d OFFSET %d", q, limit, start)
+}
+
+// 生成写入SQL，Save使用ON CONFLICT DO UPDATE处理，
+// PostgreSQL没有REPLACE操作，ON CONFLICT DO UPDATE与删除后重新写入的语义不同(未写入的字段及触发器)，因此不支持Replace
+func (db *dbpgsql) HandleInsert (table string, keys []string, values []string, option uint8, conflict []string) (string, error) {
+    switch option {
+        case OPTION_IGNORE:
+            return buildInsertSql("INSERT", table, keys, values, " ON CONFLICT DO NOTHING"), nil
+        case OPTION_REPLACE:
+            return "", unsupportedInsertOption("pgsql", option)
+        case OPTION_SAVE:
+            suffix, err := buildOnConflictSuffix(keys, conflict)
+            if err != nil {
+                return "", err
+            }
+            return buildInsertSql("INSERT", table, keys, values, suffix), nil
+    }
+    return buildInsertSql("INSERT", table, keys, values, ""), nil
+}
+
+// 获取数据表主键字段
+func (db *dbpgsql) GetPrimaryKeys (link *sql.DB, table string) ([]string, error) {
+    rows, err := link.Query(`SELECT a.attname FROM pg_index i
+        JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+        WHERE i.indrelid = $1::regclass AND i.indisprimary`, table)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    keys := make([]string, 0)
+    for rows.Next() {
+        var key string
+        if err := rows.Scan(&key); err != nil {
+            return nil, err
+        }
+        keys = append(keys, key)
+    }
+    return keys, rows.Err()
+}
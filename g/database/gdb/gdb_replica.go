@@ -0,0 +1,187 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 从库(读库)负载均衡及健康检查.
+
+package gdb
+
+import (
+    "fmt"
+    "sync"
+    "time"
+    "context"
+    "database/sql"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/util/grand"
+    "gitee.com/johng/gf/g/container/gtype"
+)
+
+const (
+    gDEFAULT_HEALTH_CHECK_INTERVAL = 10 * time.Second // 默认从库健康检查间隔
+    gDEFAULT_HEALTH_CHECK_TIMEOUT  = 3  * time.Second // 从库健康检查(Ping)超时时间
+)
+
+// 从库节点
+type dbSlave struct {
+    link    *sql.DB       // 数据库链接
+    weight  int           // 负载均衡权重(ConfigNode.Priority)
+    healthy *gtype.Bool   // 健康状态(同一节点的所有Db对象共享)
+    checker *slaveChecker // 节点健康检查对象
+}
+
+// 从库节点健康检查对象，同一节点的所有Db对象共享，按照引用计数管理，所有引用的Db对象关闭后停止检查
+type slaveChecker struct {
+    key     string        // 节点标识
+    refs    int           // 引用计数
+    healthy *gtype.Bool   // 健康状态
+    closed  chan struct{} // 关闭时通知检查goroutine退出
+}
+
+// 从库健康检查间隔
+var healthCheckInterval = gtype.NewInt64(int64(gDEFAULT_HEALTH_CHECK_INTERVAL))
+
+// 从库节点健康检查对象，键名为节点标识。
+// 由于Db对象可能被频繁创建，健康检查按照节点进行，每个节点只有一个检查goroutine，检查结果由所有Db对象共享。
+var (
+    slaveCheckersMu sync.Mutex
+    slaveCheckers   = make(map[string]*slaveChecker)
+)
+
+// 设置从库健康检查间隔(默认10秒)，不健康的从库不再分配读请求，所有从库都不健康时读请求使用主库
+func SetHealthCheckInterval(d time.Duration) {
+    if d > 0 {
+        healthCheckInterval.Set(int64(d))
+    }
+}
+
+// 创建从库节点，并开启该节点的健康检查
+func newDbSlave(driver Driver, node *ConfigNode) (*dbSlave, error) {
+    link, err := driver.Open(node)
+    if err != nil {
+        return nil, err
+    }
+    weight := node.Priority
+    if weight <= 0 {
+        weight = 1
+    }
+    checker := acquireSlaveChecker(driver, node)
+    return &dbSlave {
+        link    : link,
+        weight  : weight,
+        healthy : checker.healthy,
+        checker : checker,
+    }, nil
+}
+
+// 关闭从库节点链接，并释放对健康检查的引用
+func (s *dbSlave) close() error {
+    if s.checker != nil {
+        releaseSlaveChecker(s.checker)
+        s.checker = nil
+    }
+    return s.link.Close()
+}
+
+// 获取从库节点的健康检查对象并增加引用计数，第一次获取时开启该节点的健康检查
+func acquireSlaveChecker(driver Driver, node *ConfigNode) *slaveChecker {
+    key := getNodeKey(node)
+    slaveCheckersMu.Lock()
+    defer slaveCheckersMu.Unlock()
+    checker, ok := slaveCheckers[key]
+    if !ok {
+        checker = &slaveChecker {
+            key     : key,
+            healthy : gtype.NewBool(true),
+            closed  : make(chan struct{}),
+        }
+        slaveCheckers[key] = checker
+        go checker.run(driver, *node)
+    }
+    checker.refs++
+    return checker
+}
+
+// 减少健康检查对象的引用计数，没有引用时停止检查
+func releaseSlaveChecker(checker *slaveChecker) {
+    slaveCheckersMu.Lock()
+    defer slaveCheckersMu.Unlock()
+    checker.refs--
+    if checker.refs == 0 {
+        delete(slaveCheckers, checker.key)
+        close(checker.closed)
+    }
+}
+
+// 节点标识
+func getNodeKey(node *ConfigNode) string {
+    if node.Linkinfo != "" {
+        return node.Type + "#" + node.Linkinfo
+    }
+    return fmt.Sprintf("%s#%s@%s:%s/%s", node.Type, node.User, node.Host, node.Port, node.Name)
+}
+
+// 从库健康检查循环，检查使用独立的数据库链接，健康检查对象关闭时关闭该链接并退出
+func (c *slaveChecker) run(driver Driver, node ConfigNode) {
+    var link *sql.DB
+    defer func() {
+        if link != nil {
+            link.Close()
+        }
+    }()
+    // 日志中的节点名称(Linkinfo中可能包含密码，不能输出)
+    name := fmt.Sprintf("%s:%s/%s", node.Host, node.Port, node.Name)
+    if node.Linkinfo != "" {
+        name = node.Type + " linkinfo node"
+    }
+    for {
+        select {
+            case <- c.closed:
+                return
+            case <- time.After(time.Duration(healthCheckInterval.Val())):
+        }
+        var err error
+        if link == nil {
+            if link, err = driver.Open(&node); err == nil {
+                link.SetMaxIdleConns(1)
+                link.SetMaxOpenConns(1)
+            }
+        }
+        if err == nil {
+            ctx, cancel := context.WithTimeout(context.Background(), gDEFAULT_HEALTH_CHECK_TIMEOUT)
+            err = link.PingContext(ctx)
+            cancel()
+        }
+        if err != nil && c.healthy.Val() {
+            glog.Warningfln("gdb: slave %s is unhealthy and ejected: %s", name, err.Error())
+        } else if err == nil && !c.healthy.Val() {
+            glog.Infofln("gdb: slave %s is healthy and recovered", name)
+        }
+        c.healthy.Set(err == nil)
+    }
+}
+
+// 按照权重从健康的从库中选择一个链接用于读操作，没有从库或者所有从库都不健康时返回主库链接
+func (db *Db) getSlave() *sql.DB {
+    total := 0
+    for _, v := range db.slaves {
+        if v.healthy.Val() {
+            total += v.weight
+        }
+    }
+    if total == 0 {
+        return db.master
+    }
+    r := grand.Rand(0, total - 1)
+    for _, v := range db.slaves {
+        if !v.healthy.Val() {
+            continue
+        }
+        if r < v.weight {
+            return v.link
+        }
+        r -= v.weight
+    }
+    return db.master
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+// 从库负载均衡及健康检查单元测试(不需要数据库连接)
+// go test *.go
+
+package gdb
+
+import (
+    "testing"
+    "database/sql"
+    "gitee.com/johng/gf/g/container/gtype"
+)
+
+func newReplicaTestDb(weights...int) *Db {
+    db := &Db{master : new(sql.DB)}
+    for _, w := range weights {
+        db.slaves = append(db.slaves, &dbSlave {
+            link    : new(sql.DB),
+            weight  : w,
+            healthy : gtype.NewBool(true),
+        })
+    }
+    return db
+}
+
+func Test_GetSlaveWeight(t *testing.T) {
+    db     := newReplicaTestDb(1, 3)
+    counts := make(map[*sql.DB]int)
+    for i := 0; i < 4000; i++ {
+        counts[db.getSlave()]++
+    }
+    if counts[db.master] != 0 {
+        t.Errorf("master should not be selected when slaves are healthy")
+    }
+    // 权重1:3，允许一定的随机误差
+    if n := counts[db.slaves[0].link]; n < 800 || n > 1200 {
+        t.Errorf("unexpected selection count for weight 1: %d", n)
+    }
+    if n := counts[db.slaves[1].link]; n < 2800 || n > 3200 {
+        t.Errorf("unexpected selection count for weight 3: %d", n)
+    }
+}
+
+func Test_GetSlaveUnhealthy(t *testing.T) {
+    db := newReplicaTestDb(1, 3)
+    db.slaves[1].healthy.Set(false)
+    for i := 0; i < 100; i++ {
+        if db.getSlave() != db.slaves[0].link {
+            t.Fatalf("unhealthy slave should not be selected")
+        }
+    }
+    db.slaves[0].healthy.Set(false)
+    if db.getSlave() != db.master {
+        t.Errorf("master should be selected when all slaves are unhealthy")
+    }
+    db.slaves[1].healthy.Set(true)
+    if db.getSlave() != db.slaves[1].link {
+        t.Errorf("recovered slave should be selected")
+    }
+    empty := newReplicaTestDb()
+    if empty.getSlave() != empty.master {
+        t.Errorf("master should be selected when there is no slave")
+    }
+}
+
+func Test_SlaveCheckerRelease(t *testing.T) {
+    node := &ConfigNode{Type : "replica-test", Host : "127.0.0.1", Port : "1", Name : "test"}
+    c1   := acquireSlaveChecker(nil, node)
+    c2   := acquireSlaveChecker(nil, node)
+    if c1 != c2 || c1.refs != 2 {
+        t.Fatalf("checker should be shared by the same node")
+    }
+    releaseSlaveChecker(c1)
+    select {
+        case <- c1.closed:
+            t.Fatalf("checker should not be closed while still referenced")
+        default:
+    }
+    releaseSlaveChecker(c2)
+    select {
+        case <- c1.closed:
+        default:
+            t.Fatalf("checker should be closed when no longer referenced")
+    }
+    slaveCheckersMu.Lock()
+    _, ok := slaveCheckers[c1.key]
+    slaveCheckersMu.Unlock()
+    if ok {
+        t.Errorf("closed checker should be removed")
+    }
+    if c3 := acquireSlaveChecker(nil, node); c3 == c1 {
+        t.Errorf("a new checker should be created after the old one is closed")
+    } else {
+        releaseSlaveChecker(c3)
+    }
+}
@@ -8,16 +8,16 @@
 package gdb
 
 import (
+	"fmt"
 	"database/sql"
+	"gitee.com/johng/gf/g/util/gconv"
 )
 
 // 使用时需要import:
 // _ "github.com/mattn/go-sqlite3"
 
 // 数据库链接对象
-type dbsqlite struct {
-	Db
-}
+type dbsqlite struct {}
 
 func (db *dbsqlite) Open(c *ConfigNode) (*sql.DB, error) {
 	var source string
@@ -34,19 +34,73 @@ func (db *dbsqlite) Open(c *ConfigNode) (*sql.DB, error) {
 }
 
 // 获得关键字操作符 - 左
-func (db *dbsqlite) getQuoteCharLeft() string {
+func (db *dbsqlite) GetQuoteCharLeft() string {
 	return "`"
 }
 
 // 获得关键字操作符 - 右
-func (db *dbsqlite) getQuoteCharRight() string {
+func (db *dbsqlite) GetQuoteCharRight() string {
 	return "`"
 }
 
 // 在执行sql之前对sql进行进一步处理
-// @todo 需要增加对Save方法的支持，可使用正则来实现替换，
-// @todo 将ON DUPLICATE KEY UPDATE触发器修改为两条SQL语句(INSERT OR IGNORE & UPDATE)
-func (db *dbsqlite) handleSqlBeforeExec(q *string) *string {
-
+func (db *dbsqlite) HandleSqlBeforeExec(q *string) *string {
 	return q
 }
+
+// 为查询SQL增加分页语句
+func (db *dbsqlite) HandleLimit(q string, start, limit int) string {
+	return fmt.Sprintf("%s LIMIT %d OFFSET %d", q, limit, start)
+}
+
+// 生成写入SQL，Save使用ON CONFLICT DO UPDATE处理(需要SQLite 3.24及以上版本)
+func (db *dbsqlite) HandleInsert(table string, keys []string, values []string, option uint8, conflict []string) (string, error) {
+	switch option {
+		case OPTION_REPLACE:
+			return buildInsertSql("REPLACE", table, keys, values, ""), nil
+		case OPTION_IGNORE:
+			return buildInsertSql("INSERT OR IGNORE", table, keys, values, ""), nil
+		case OPTION_SAVE:
+			suffix, err := buildOnConflictSuffix(keys, conflict)
+			if err != nil {
+				return "", err
+			}
+			return buildInsertSql("INSERT", table, keys, values, suffix), nil
+	}
+	return buildInsertSql("INSERT", table, keys, values, ""), nil
+}
+
+// 获取数据表主键字段(按照主键中的顺序)
+func (db *dbsqlite) GetPrimaryKeys(link *sql.DB, table string) ([]string, error) {
+	rows, err := link.Query(fmt.Sprintf("PRAGMA table_info(`%s`)", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[int]string)
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		record := make(map[string]interface{})
+		for i := range values {
+			values[i] = new(interface{})
+		}
+		if err := rows.Scan(values...); err != nil {
+			return nil, err
+		}
+		for i, name := range columns {
+			record[name] = *(values[i].(*interface{}))
+		}
+		if pk := gconv.Int(record["pk"]); pk > 0 {
+			keys[pk] = gconv.String(record["name"])
+		}
+	}
+	result := make([]string, 0, len(keys))
+	for i := 1; i <= len(keys); i++ {
+		result = append(result, keys[i])
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,151 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// SQL执行统计.
+
+package gdb
+
+import (
+    "sort"
+    "sync"
+    "time"
+    "bytes"
+    "strconv"
+    "sync/atomic"
+    "gitee.com/johng/gf/g/os/gcache"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// SQL执行耗时分布区间上限(秒)
+var statsBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// SQL执行统计信息(按照数据库配置分组及执行方法统计)
+type Stats struct {
+    Group   string    // 数据库配置分组名称
+    Func    string    // 执行方法名称，同Sql.Func，如：DB:Query, DB:Exec, TX:Query, TX:Exec
+    Count   int64     // 执行次数
+    Errors  int64     // 执行失败次数
+    Seconds float64   // 累计执行耗时(秒)
+    Buckets []float64 // 耗时分布区间上限(秒)
+    Counts  []int64   // 各耗时区间的执行次数(非累计)，长度比Buckets多1，最后一项为超过最大区间的次数
+}
+
+// 查询缓存统计信息(按照数据库配置分组统计)
+type CacheStats struct {
+    Group  string // 数据库配置分组名称
+    Hits   int64  // 缓存命中次数
+    Misses int64  // 缓存未命中次数
+}
+
+// SQL执行统计项，各计数均通过原子操作更新
+type statsItem struct {
+    count  int64   // 执行次数(原子操作的字段放在最前以保证64位对齐)
+    errors int64   // 执行失败次数
+    nanos  int64   // 累计执行耗时(纳秒)
+    group  string  // 数据库配置分组名称
+    fun    string  // 执行方法名称
+    counts []int64 // 各耗时区间的执行次数(非累计)
+}
+
+// 全局SQL执行统计，键名为：分组名称 + "@" + 执行方法名称，键值为*statsItem
+var stats = sync.Map{}
+
+func init() {
+    ghttp.RegisterMetricsCollector(writeMetrics)
+}
+
+// 记录一次SQL执行统计
+func (db *Db) addStats(function string, start time.Time, err error) {
+    duration := time.Since(start)
+    key      := db.group + "@" + function
+    v, ok    := stats.Load(key)
+    if !ok {
+        v, _ = stats.LoadOrStore(key, &statsItem {
+            group  : db.group,
+            fun    : function,
+            counts : make([]int64, len(statsBuckets) + 1),
+        })
+    }
+    item := v.(*statsItem)
+    atomic.AddInt64(&item.count, 1)
+    atomic.AddInt64(&item.nanos, int64(duration))
+    if err != nil {
+        atomic.AddInt64(&item.errors, 1)
+    }
+    atomic.AddInt64(&item.counts[sort.SearchFloat64s(statsBuckets, duration.Seconds())], 1)
+}
+
+// 获取所有的SQL执行统计信息(按照分组名称及执行方法排序)，
+// 由于统计项并发更新，各字段之间不保证严格一致
+func GetStats() []Stats {
+    array := make([]Stats, 0)
+    stats.Range(func(k, v interface{}) bool {
+        item   := v.(*statsItem)
+        counts := make([]int64, len(item.counts))
+        for i := range item.counts {
+            counts[i] = atomic.LoadInt64(&item.counts[i])
+        }
+        array = append(array, Stats {
+            Group   : item.group,
+            Func    : item.fun,
+            Count   : atomic.LoadInt64(&item.count),
+            Errors  : atomic.LoadInt64(&item.errors),
+            Seconds : time.Duration(atomic.LoadInt64(&item.nanos)).Seconds(),
+            Buckets : statsBuckets,
+            Counts  : counts,
+        })
+        return true
+    })
+    sort.Slice(array, func(i, j int) bool {
+        if array[i].Group != array[j].Group {
+            return array[i].Group < array[j].Group
+        }
+        return array[i].Func < array[j].Func
+    })
+    return array
+}
+
+// 获取所有分组的查询缓存命中统计信息(按照分组名称排序)
+func GetCacheStats() []CacheStats {
+    array := make([]CacheStats, 0)
+    dbCaches.RLockFunc(func(m map[string]interface{}) {
+        for group, v := range m {
+            hits, misses := v.(*gcache.Cache).Stats()
+            array = append(array, CacheStats {
+                Group  : group,
+                Hits   : hits,
+                Misses : misses,
+            })
+        }
+    })
+    sort.Slice(array, func(i, j int) bool {
+        return array[i].Group < array[j].Group
+    })
+    return array
+}
+
+// 输出数据库相关的监控指标(注册到ghttp的监控指标服务)
+func writeMetrics(buffer *bytes.Buffer) {
+    stats := GetStats()
+    ghttp.WriteMetricsHeader(buffer, "gdb_query_errors_total", "counter", "Total number of failed sql executions.")
+    for _, v := range stats {
+        buffer.WriteString("gdb_query_errors_total" + ghttp.FormatMetricsLabels("group", v.Group, "func", v.Func) +
+            " " + strconv.FormatInt(v.Errors, 10) + "\n")
+    }
+    ghttp.WriteMetricsHeader(buffer, "gdb_query_duration_seconds", "histogram", "Sql execution latency in seconds.")
+    for _, v := range stats {
+        ghttp.WriteMetricsHistogram(buffer, "gdb_query_duration_seconds", []string{"group", v.Group, "func", v.Func},
+            v.Buckets, v.Counts, v.Count, v.Seconds)
+    }
+    cacheStats := GetCacheStats()
+    ghttp.WriteMetricsHeader(buffer, "gdb_cache_hits_total", "counter", "Total number of sql query cache hits.")
+    for _, v := range cacheStats {
+        buffer.WriteString("gdb_cache_hits_total" + ghttp.FormatMetricsLabels("group", v.Group) + " " + strconv.FormatInt(v.Hits, 10) + "\n")
+    }
+    ghttp.WriteMetricsHeader(buffer, "gdb_cache_misses_total", "counter", "Total number of sql query cache misses.")
+    for _, v := range cacheStats {
+        buffer.WriteString("gdb_cache_misses_total" + ghttp.FormatMetricsLabels("group", v.Group) + " " + strconv.FormatInt(v.Misses, 10) + "\n")
+    }
+}
@@ -8,9 +8,9 @@ package gdb
 
 import (
     "fmt"
-    "errors"
     "strings"
     "reflect"
+    "time"
     "database/sql"
     "gitee.com/johng/gf/g/os/gtime"
     "gitee.com/johng/gf/g/util/gconv"
@@ -37,7 +37,8 @@ func (tx *Tx) Rollback() error {
 func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
     var err  error
     var rows *sql.Rows
-    p := tx.db.link.handleSqlBeforeExec(&query)
+    p := tx.db.driver.HandleSqlBeforeExec(&query)
+    start := time.Now()
     if tx.db.debug.Val() {
         militime1 := gtime.Millisecond()
         rows, err  = tx.tx.Query(*p, args ...)
@@ -55,6 +56,7 @@ func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
     } else {
         rows, err  = tx.tx.Query(*p, args ...)
     }
+    tx.db.addStats("TX:Query", start, err)
     if err == nil {
         return rows, nil
     } else {
@@ -67,7 +69,8 @@ func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
 func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
     var err    error
     var result sql.Result
-    p := tx.db.link.handleSqlBeforeExec(&query)
+    p := tx.db.driver.HandleSqlBeforeExec(&query)
+    start := time.Now()
     if tx.db.debug.Val() {
         militime1  := gtime.Millisecond()
         result, err = tx.tx.Exec(*p, args ...)
@@ -85,6 +88,7 @@ func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
     } else {
         result, err = tx.tx.Exec(*p, args ...)
     }
+    tx.db.addStats("TX:Exec", start, err)
     return result, tx.db.formatError(err, p, args...)
 }
 
@@ -147,6 +151,15 @@ func (tx *Tx) GetStruct(obj interface{}, query string, args ...interface{}) erro
     return one.ToStruct(obj)
 }
 
+// 数据库查询，获取查询结果集，自动映射数据到给定的struct数组中，objs为数组指针，如：&[]User或者&[]*User
+func (tx *Tx) GetStructs(objs interface{}, query string, args ...interface{}) error {
+    list, err := tx.GetAll(query, args...)
+    if err != nil {
+        return err
+    }
+    return list.ToStructs(objs)
+}
+
 
 // 数据库查询，获取查询字段值
 func (tx *Tx) GetValue(query string, args ...interface{}) (Value, error) {
@@ -182,7 +195,7 @@ func (tx *Tx) Select(tables, fields string, condition interface{}, groupBy, orde
         s += fmt.Sprintf("ORDER BY %s ", orderBy)
     }
     if limit > 0 {
-        s += fmt.Sprintf("LIMIT %d,%d ", first, limit)
+        s = tx.db.driver.HandleLimit(strings.TrimSpace(s), first, limit)
     }
     return tx.GetAll(s, args ... )
 }
@@ -193,36 +206,9 @@ func (tx *Tx) Prepare(query string) (*sql.Stmt, error) {
     return tx.tx.Prepare(query)
 }
 
-// insert、replace, save， ignore操作
-// 0: insert:  仅仅执行写入操作，如果存在冲突的主键或者唯一索引，那么报错返回
-// 1: replace: 如果数据存在(主键或者唯一索引)，那么删除后重新写入一条
-// 2: save:    如果数据存在(主键或者唯一索引)，那么更新，否则写入一条新数据
-// 3: ignore:  如果数据存在(主键或者唯一索引)，那么什么也不做
-func (tx *Tx) insert(table string, data Map, option uint8) (sql.Result, error) {
-    var keys   []string
-    var values []string
-    var params []interface{}
-    for k, v := range data {
-        keys   = append(keys,   tx.db.charl + k + tx.db.charr)
-        values = append(values, "?")
-        params = append(params, gconv.String(v))
-    }
-    operation := tx.db.getInsertOperationByOption(option)
-    updatestr := ""
-    if option == OPTION_SAVE {
-        var updates []string
-        for k, _ := range data {
-            updates = append(updates, fmt.Sprintf("%s%s%s=VALUES(%s)", tx.db.charl, k, tx.db.charr, k))
-        }
-        updatestr = fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s", strings.Join(updates, ","))
-    }
-    return tx.Exec(
-        fmt.Sprintf("%s INTO %s%s%s(%s) VALUES(%s) %s",
-            operation, tx.db.charl, table, tx.db.charr, strings.Join(keys, ","),
-            strings.Join(values, ","),
-            updatestr),
-            params...
-    )
+// insert、replace, save， ignore操作，参考Db.insert
+func (tx *Tx) insert(table string, data Map, option uint8, conflict ...string) (sql.Result, error) {
+    return tx.batchInsert(table, List{data}, 1, option, conflict...)
 }
 
 // CURD操作:单条数据写入, 仅仅执行写入操作，如果存在冲突的主键或者唯一索引，那么报错返回
@@ -241,63 +227,16 @@ func (tx *Tx) Save(table string, data Map) (sql.Result, error) {
 }
 
 // 批量写入数据
-func (tx *Tx) batchInsert(table string, list List, batch int, option uint8) (sql.Result, error) {
-    var keys    []string
-    var values  []string
-    var bvalues []string
-    var params  []interface{}
-    var result  sql.Result
-    var size = len(list)
-    // 判断长度
-    if size < 1 {
-        return result, errors.New("empty data list")
-    }
-    // 首先获取字段名称及记录长度
-    for k, _ := range list[0] {
-        keys   = append(keys,   k)
-        values = append(values, "?")
-    }
-    keyStr         := tx.db.charl + strings.Join(keys, tx.db.charl + "," + tx.db.charr) + tx.db.charr
-    valueHolderStr := "(" + strings.Join(values, ",") + ")"
-    // 操作判断
-    operation := tx.db.getInsertOperationByOption(option)
-    updatestr := ""
-    if option == OPTION_SAVE {
-        var updates []string
-        for _, k := range keys {
-            updates = append(updates, fmt.Sprintf("%s%s%s=VALUES(%s)", tx.db.charl, k, tx.db.charr, k))
-        }
-        updatestr = fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s", strings.Join(updates, ","))
-    }
-    // 构造批量写入数据格式(注意map的遍历是无序的)
-    for i := 0; i < size; i++ {
-        for _, k := range keys {
-            params = append(params, gconv.String(list[i][k]))
-        }
-        bvalues = append(bvalues, valueHolderStr)
-        if len(bvalues) == batch {
-            r, err := tx.Exec(fmt.Sprintf("%s INTO %s%s%s(%s) VALUES%s %s",
-                operation, tx.db.charl, table, tx.db.charr, keyStr, strings.Join(bvalues, ","),
-                updatestr),
-                params...)
-            if err != nil {
-                return result, err
-            }
-            result  = r
-            params  = params[:0]
-            bvalues = bvalues[:0]
-        }
+func (tx *Tx) batchInsert(table string, list List, batch int, option uint8, conflict ...string) (sql.Result, error) {
+    var result sql.Result
+    sqls, err := tx.db.buildInsertSqls(table, list, batch, option, conflict)
+    if err != nil {
+        return result, err
     }
-    // 处理最后不构成指定批量的数据
-    if len(bvalues) > 0 {
-        r, err := tx.Exec(fmt.Sprintf("%s INTO %s%s%s(%s) VALUES%s %s",
-            operation, tx.db.charl, table, tx.db.charr, keyStr, strings.Join(bvalues, ","),
-            updatestr),
-            params...)
-        if err != nil {
+    for _, v := range sqls {
+        if result, err = tx.Exec(v.sql, v.args...); err != nil {
             return result, err
         }
-        result = r
     }
     return result, nil
 }
@@ -327,8 +266,8 @@ func (tx *Tx) Update(table string, data interface{}, condition interface{}, args
         var fields []string
         keys := refValue.MapKeys()
         for _, k := range keys {
-            fields = append(fields, fmt.Sprintf("%s%s%s=?", tx.db.charl, k, tx.db.charr))
-            params = append(params, gconv.String(refValue.MapIndex(k).Interface()))
+            fields = append(fields, tx.db.quoteWord(gconv.String(k.Interface())) + "=?")
+            params = append(params, convertParam(refValue.MapIndex(k).Interface()))
             updates = strings.Join(fields,   ",")
         }
     } else {
@@ -337,11 +276,11 @@ func (tx *Tx) Update(table string, data interface{}, condition interface{}, args
     for _, v := range args {
         params = append(params, gconv.String(v))
     }
-    return tx.Exec(fmt.Sprintf("UPDATE %s%s%s SET %s WHERE %s", tx.db.charl, table, tx.db.charr, updates, tx.db.formatCondition(condition)), params...)
+    return tx.Exec(fmt.Sprintf("UPDATE %s SET %s WHERE %s", tx.db.quoteWord(table), updates, tx.db.formatCondition(condition)), params...)
 }
 
 // CURD操作:删除数据
 func (tx *Tx) Delete(table string, condition interface{}, args ...interface{}) (sql.Result, error) {
-    return tx.Exec(fmt.Sprintf("DELETE FROM %s%s%s WHERE %s", tx.db.charl, table, tx.db.charr, tx.db.formatCondition(condition)), args...)
+    return tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s", tx.db.quoteWord(table), tx.db.formatCondition(condition)), args...)
 }
 
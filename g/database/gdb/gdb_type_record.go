@@ -8,7 +8,6 @@ package gdb
 
 import (
     "gitee.com/johng/gf/g/encoding/gparser"
-    "gitee.com/johng/gf/g/util/gconv"
 )
 
 // 将记录结果转换为JSON字符串
@@ -31,12 +30,3 @@ func (r Record) ToMap() Map {
     }
     return m
 }
-
-// 将Map变量映射到指定的struct对象中，注意参数应当是一个对象的指针
-func (r Record) ToStruct(obj interface{}) error {
-    m := make(map[string]interface{})
-    for k, v := range r {
-        m[k] = v.String()
-    }
-    return gconv.MapToStruct(m, obj)
-}
@@ -124,3 +124,7 @@ func (r *Redis) Send(command string, args ...interface{}) error {
     return r.conn.Send(command, args...)
 }
 
+// 获取异步命令或者订阅(SUBSCRIBE)消息的返回结果，没有结果时阻塞等待
+func (r *Redis) Receive() (interface{}, error) {
+    return r.conn.Receive()
+}
@@ -0,0 +1,114 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// HTTP客户端Cookie保持及持久化.
+
+package ghttp
+
+import (
+    "sync"
+    "time"
+    "errors"
+    "net/url"
+    "net/http"
+    "io/ioutil"
+    "encoding/json"
+    "net/http/cookiejar"
+    "gitee.com/johng/gf/g/os/gfile"
+)
+
+// 可持久化的Cookie保持对象，在标准库cookiejar的基础上记录服务端设置的Cookie，以便保存到文件
+type clientCookieJar struct {
+    *cookiejar.Jar
+    mu      sync.Mutex
+    cookies map[string]*clientCookie // 键名为：请求域名|Cookie域名|Cookie路径|Cookie名称
+}
+
+// 保存的Cookie项
+type clientCookie struct {
+    Url    string       `json:"url"`    // 设置该Cookie的请求地址
+    Cookie *http.Cookie `json:"cookie"` // Cookie内容(MaxAge已转换为Expires)
+}
+
+// 创建可持久化的Cookie保持对象
+func newClientCookieJar() *clientCookieJar {
+    jar, _ := cookiejar.New(nil)
+    return &clientCookieJar {
+        Jar     : jar,
+        cookies : make(map[string]*clientCookie),
+    }
+}
+
+// 设置服务端返回的Cookie，同时记录以便保存
+func (j *clientCookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+    j.Jar.SetCookies(u, cookies)
+    j.mu.Lock()
+    defer j.mu.Unlock()
+    for _, cookie := range cookies {
+        v := *cookie
+        // MaxAge为相对时间，转换为绝对的过期时间，以便加载时计算正确的有效期
+        if v.MaxAge > 0 {
+            v.Expires = time.Now().Add(time.Duration(v.MaxAge) * time.Second)
+            v.MaxAge  = 0
+        }
+        key := u.Hostname() + "|" + v.Domain + "|" + v.Path + "|" + v.Name
+        if v.MaxAge < 0 || (!v.Expires.IsZero() && v.Expires.Before(time.Now())) {
+            delete(j.cookies, key)
+            continue
+        }
+        j.cookies[key] = &clientCookie {
+            Url    : u.Scheme + "://" + u.Host + u.Path,
+            Cookie : &v,
+        }
+    }
+}
+
+// 将Cookie保持中未过期的Cookie保存到文件(JSON格式)，以便程序重启后通过LoadCookies恢复，
+// 没有设置过期时间的会话Cookie同样会被保存
+func (c *Client) SaveCookies(path string) error {
+    jar, ok := c.Jar.(*clientCookieJar)
+    if !ok {
+        return errors.New("cookie jar does not support saving")
+    }
+    jar.mu.Lock()
+    list := make([]*clientCookie, 0, len(jar.cookies))
+    for _, v := range jar.cookies {
+        if v.Cookie.Expires.IsZero() || v.Cookie.Expires.After(time.Now()) {
+            list = append(list, v)
+        }
+    }
+    jar.mu.Unlock()
+    content, err := json.Marshal(list)
+    if err != nil {
+        return err
+    }
+    return gfile.PutBinContents(path, content)
+}
+
+// 从SaveCookies保存的文件中加载Cookie到Cookie保持中，已过期的Cookie会被忽略
+func (c *Client) LoadCookies(path string) error {
+    if c.Jar == nil {
+        return errors.New("cookie jar is not enabled")
+    }
+    content, err := ioutil.ReadFile(path)
+    if err != nil {
+        return err
+    }
+    list := make([]*clientCookie, 0)
+    if err := json.Unmarshal(content, &list); err != nil {
+        return err
+    }
+    for _, v := range list {
+        if v.Cookie == nil || (!v.Cookie.Expires.IsZero() && v.Cookie.Expires.Before(time.Now())) {
+            continue
+        }
+        u, err := url.Parse(v.Url)
+        if err != nil {
+            return err
+        }
+        c.Jar.SetCookies(u, []*http.Cookie{v.Cookie})
+    }
+    return nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// HTTP客户端请求拦截器.
+
+package ghttp
+
+import (
+    "net/http"
+)
+
+// 执行请求的方法
+type ClientHandler func(req *http.Request) (*http.Response, error)
+
+// 请求拦截器，可在调用next前修改请求(如签名、鉴权)，在调用next后处理返回结果(如日志、统计)，
+// 也可以不调用next直接返回结果(如mock)
+type ClientInterceptor func(req *http.Request, next ClientHandler) (*http.Response, error)
+
+// 添加请求拦截器，按照添加顺序执行，先添加的拦截器位于外层。
+// 拦截器对每一次实际发起的请求生效(包括失败重试的请求)。
+func (c *Client) Use(interceptors...ClientInterceptor) {
+    c.handlers = append(c.handlers, interceptors...)
+}
+
+// 经过拦截器链执行请求
+func (c *Client) intercept(req *http.Request) (*http.Response, error) {
+    return c.handler(0)(req)
+}
+
+// 获取拦截器链中第index个拦截器对应的执行方法，拦截器链末端为底层http client的请求方法
+func (c *Client) handler(index int) ClientHandler {
+    if index >= len(c.handlers) {
+        return c.Do
+    }
+    return func(req *http.Request) (*http.Response, error) {
+        return c.handlers[index](req, c.handler(index + 1))
+    }
+}
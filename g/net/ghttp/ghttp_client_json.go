@@ -0,0 +1,96 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// HTTP客户端JSON请求.
+
+package ghttp
+
+import (
+    "fmt"
+    "gitee.com/johng/gf/g/encoding/gjson"
+)
+
+// GET请求并将返回的JSON内容解析为gjson.Json对象
+func (c *Client) GetJson(url string) (*gjson.Json, error) {
+    content, err := c.jsonRequest("GET", url, nil)
+    if err != nil {
+        return nil, err
+    }
+    return gjson.DecodeToJson(content)
+}
+
+// GET请求并将返回的JSON内容解析到给定的变量(指针)，如struct
+func (c *Client) GetJsonTo(url string, pointer interface{}) error {
+    content, err := c.jsonRequest("GET", url, nil)
+    if err != nil {
+        return err
+    }
+    return gjson.DecodeTo(content, pointer)
+}
+
+// 以JSON方式(application/json)提交数据，并将返回的JSON内容解析为gjson.Json对象，
+// data为string/[]byte时原样提交，其他类型编码为JSON后提交
+func (c *Client) PostJson(url string, data interface{}) (*gjson.Json, error) {
+    content, err := c.jsonRequest("POST", url, data)
+    if err != nil {
+        return nil, err
+    }
+    return gjson.DecodeToJson(content)
+}
+
+// 以JSON方式(application/json)提交数据，并将返回的JSON内容解析到给定的变量(指针)
+func (c *Client) PostJsonTo(url string, data interface{}, pointer interface{}) error {
+    content, err := c.jsonRequest("POST", url, data)
+    if err != nil {
+        return err
+    }
+    return gjson.DecodeTo(content, pointer)
+}
+
+// 执行JSON请求并返回内容，HTTP状态码不为2xx时返回错误
+func (c *Client) jsonRequest(method, url string, data interface{}) ([]byte, error) {
+    var body []byte
+    switch v := data.(type) {
+        case nil:
+        case string:
+            body = []byte(v)
+        case []byte:
+            body = v
+        default:
+            b, err := gjson.Encode(v)
+            if err != nil {
+                return nil, err
+            }
+            body = b
+    }
+    contentType := ""
+    if body != nil {
+        contentType = "application/json"
+    }
+    if _, ok := c.header["Accept"]; !ok {
+        c = c.WithContext(c.ctx)
+        c.header["Accept"] = "application/json"
+    }
+    r, err := c.doRequest(method, url, contentType, body)
+    if err != nil {
+        return nil, err
+    }
+    defer r.Close()
+    content := r.ReadAll()
+    if r.StatusCode < 200 || r.StatusCode >= 300 {
+        return nil, fmt.Errorf("%s %s: unexpected status %s", method, url, r.Status)
+    }
+    return content, nil
+}
+
+// GET请求并将返回的JSON内容解析为gjson.Json对象
+func GetJson(url string) (*gjson.Json, error) {
+    return NewClient().GetJson(url)
+}
+
+// 以JSON方式提交数据，并将返回的JSON内容解析为gjson.Json对象
+func PostJson(url string, data interface{}) (*gjson.Json, error) {
+    return NewClient().PostJson(url, data)
+}
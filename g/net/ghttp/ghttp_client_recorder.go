@@ -0,0 +1,154 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// HTTP客户端请求录制及回放(用于脱离网络的测试).
+
+package ghttp
+
+import (
+    "fmt"
+    "mime"
+    "bytes"
+    "errors"
+    "strings"
+    "net/http"
+    "io/ioutil"
+    "unicode/utf8"
+    "encoding/json"
+    "encoding/base64"
+    "gitee.com/johng/gf/g/os/gfile"
+    "gitee.com/johng/gf/g/crypto/gsha1"
+)
+
+const (
+    CLIENT_RECORD_MODE_RECORD = 1 // 录制模式：发起真实请求，并将返回结果保存到录制文件
+    CLIENT_RECORD_MODE_REPLAY = 2 // 回放模式：只从录制文件返回结果，不发起真实请求，录制文件不存在时返回错误
+    CLIENT_RECORD_MODE_AUTO   = 3 // 自动模式：录制文件存在时回放，否则发起真实请求并录制
+)
+
+// 请求录制/回放Transport
+type clientRecorder struct {
+    dir       string            // 录制文件保存目录
+    mode      int               // 录制模式
+    transport http.RoundTripper // 真实请求使用的Transport
+}
+
+// 录制文件内容
+type clientRecord struct {
+    Method     string      `json:"method"`           // 请求方法
+    Url        string      `json:"url"`              // 请求地址
+    StatusCode int         `json:"status"`           // 返回状态码
+    Header     http.Header `json:"header"`           // 返回Header
+    Body       string      `json:"body"`             // 返回内容
+    Base64     bool        `json:"base64,omitempty"` // 返回内容是否为base64编码(二进制内容)
+}
+
+// 设置请求录制/回放，录制文件以JSON格式保存在dir目录下，文件名由请求方法、地址及提交内容计算得到，
+// 测试时可使用CLIENT_RECORD_MODE_REPLAY模式基于录制文件运行，不需要访问网络。
+func (c *Client) SetRecorder(dir string, mode int) {
+    c.Transport = &clientRecorder {
+        dir       : dir,
+        mode      : mode,
+        transport : c.transport,
+    }
+}
+
+// 实现http.RoundTripper接口
+func (r *clientRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+    body, err := readRequestBody(req)
+    if err != nil {
+        return nil, err
+    }
+    path := r.path(req, body)
+    if r.mode != CLIENT_RECORD_MODE_RECORD && gfile.Exists(path) {
+        return r.replay(req, path)
+    }
+    if r.mode == CLIENT_RECORD_MODE_REPLAY {
+        return nil, errors.New("no recorded response for " + req.Method + " " + req.URL.String())
+    }
+    resp, err := r.transport.RoundTrip(req)
+    if err != nil {
+        return nil, err
+    }
+    content, err := ioutil.ReadAll(resp.Body)
+    resp.Body.Close()
+    if err != nil {
+        return nil, err
+    }
+    resp.Body = ioutil.NopCloser(bytes.NewReader(content))
+    record := clientRecord {
+        Method     : req.Method,
+        Url        : req.URL.String(),
+        StatusCode : resp.StatusCode,
+        Header     : resp.Header,
+    }
+    if utf8.Valid(content) {
+        record.Body   = string(content)
+    } else {
+        record.Body   = base64.StdEncoding.EncodeToString(content)
+        record.Base64 = true
+    }
+    b, err := json.MarshalIndent(record, "", "    ")
+    if err != nil {
+        return nil, err
+    }
+    if err := gfile.PutBinContents(path, b); err != nil {
+        return nil, err
+    }
+    return resp, nil
+}
+
+// 从录制文件构造返回结果
+func (r *clientRecorder) replay(req *http.Request, path string) (*http.Response, error) {
+    record := clientRecord{}
+    if err := json.Unmarshal(gfile.GetBinContents(path), &record); err != nil {
+        return nil, err
+    }
+    content := []byte(record.Body)
+    if record.Base64 {
+        b, err := base64.StdEncoding.DecodeString(record.Body)
+        if err != nil {
+            return nil, err
+        }
+        content = b
+    }
+    if record.Header == nil {
+        record.Header = make(http.Header)
+    }
+    return &http.Response {
+        Status        : fmt.Sprintf("%d %s", record.StatusCode, http.StatusText(record.StatusCode)),
+        StatusCode    : record.StatusCode,
+        Proto         : "HTTP/1.1",
+        ProtoMajor    : 1,
+        ProtoMinor    : 1,
+        Header        : record.Header,
+        Body          : ioutil.NopCloser(bytes.NewReader(content)),
+        ContentLength : int64(len(content)),
+        Request       : req,
+    }, nil
+}
+
+// 计算请求对应的录制文件路径，multipart请求的随机分隔符不参与计算，保证同样的请求对应同一录制文件
+func (r *clientRecorder) path(req *http.Request, body []byte) string {
+    if _, params, err := mime.ParseMediaType(req.Header.Get("Content-Type")); err == nil && params["boundary"] != "" {
+        body = bytes.Replace(body, []byte(params["boundary"]), nil, -1)
+    }
+    key := gsha1.EncryptString(req.Method + " " + req.URL.String() + "\n" + string(body))
+    return strings.TrimRight(r.dir, "/\\") + gfile.Separator + strings.ToLower(req.Method) + "_" + key[:16] + ".json"
+}
+
+// 读取请求提交的内容，并重置请求内容以便后续发送
+func readRequestBody(req *http.Request) ([]byte, error) {
+    if req.Body == nil || req.Body == http.NoBody {
+        return nil, nil
+    }
+    body, err := ioutil.ReadAll(req.Body)
+    req.Body.Close()
+    if err != nil {
+        return nil, err
+    }
+    req.Body = ioutil.NopCloser(bytes.NewReader(body))
+    return body, nil
+}
@@ -10,6 +10,7 @@ package ghttp
 import (
     "io/ioutil"
     "net/http"
+    "gitee.com/johng/gf/g/encoding/gjson"
 )
 
 // 客户端请求结果对象
@@ -17,8 +18,9 @@ type ClientResponse struct {
     http.Response
 }
 
-// 获取返回的数据
+// 获取返回的数据，读取完成后自动关闭HTTP链接
 func (r *ClientResponse) ReadAll() []byte {
+    defer r.Close()
     body, err := ioutil.ReadAll(r.Body)
     if err != nil {
         return nil
@@ -26,8 +28,25 @@ func (r *ClientResponse) ReadAll() []byte {
     return body
 }
 
-// 关闭返回的HTTP链接
+// 获取返回的数据(字符串)，读取完成后自动关闭HTTP链接
+func (r *ClientResponse) ReadAllString() string {
+    return string(r.ReadAll())
+}
+
+// 将返回的JSON数据解析为gjson.Json对象，读取完成后自动关闭HTTP链接
+func (r *ClientResponse) ToJson() (*gjson.Json, error) {
+    return gjson.DecodeToJson(r.ReadAll())
+}
+
+// 将返回的JSON数据解析到给定的变量(指针)，如struct，读取完成后自动关闭HTTP链接
+func (r *ClientResponse) ToStruct(pointer interface{}) error {
+    return gjson.DecodeTo(r.ReadAll(), pointer)
+}
+
+// 关闭返回的HTTP链接(可重复调用)
 func (r *ClientResponse) Close()  {
     r.Response.Close = true
-    r.Body.Close()
-}
\ No newline at end of file
+    if r.Body != nil {
+        r.Body.Close()
+    }
+}
@@ -23,6 +23,8 @@ type Request struct {
     parsedGet     *gtype.Bool         // GET参数是否已经解析
     parsedPost    *gtype.Bool         // POST参数是否已经解析
     queryVars     map[string][]string // GET参数
+    rawContent    []byte              // 原始请求输入内容(读取后缓存)
+    parsePostErr  error               // POST参数解析错误(例如请求体超过大小限制)
     routerVars    map[string][]string // 路由解析参数
     exit          *gtype.Bool         // 是否退出当前请求流程执行
     Id            int                 // 请求id(唯一)
@@ -30,12 +32,14 @@ type Request struct {
     Cookie        *Cookie             // 与当前请求绑定的Cookie对象(并发安全)
     Session       *Session            // 与当前请求绑定的Session对象(并发安全)
     Response      *Response           // 对应请求的返回数据操作对象
+    Middleware    *Middleware         // 中间件调用链对象
     Router        *Router             // 匹配到的路由对象
     EnterTime     int64               // 请求进入时间(微秒)
     LeaveTime     int64               // 请求完成时间(微秒)
     Param         interface{}         // 开发者自定义参数
     parsedHost    *gtype.String       // 解析过后不带端口号的服务器域名名称
     clientIp      *gtype.String       // 解析过后的客户端IP地址
+    trace         *requestTrace       // 请求ID及链路追踪上下文
     isFileRequest bool                // 是否为静态文件请求(非服务请求，当静态文件存在时，优先级会被服务请求高，被识别为文件请求)
 }
 
@@ -59,6 +63,8 @@ func newRequest(s *Server, r *http.Request, w http.ResponseWriter) *Request {
     request.Cookie           = GetCookie(request)
     request.Session          = GetSession(request)
     request.Response.request = request
+    request.Middleware       = &Middleware{request : request}
+    request.initTrace()
     return request
 }
 
@@ -79,10 +85,12 @@ func (r *Request) Get(key string, def ... string) string {
     return r.GetRequestString(key, def...)
 }
 
-// 获取原始请求输入字符串，注意：只能获取一次，读完就没了
+// 获取原始请求输入字符串，读取后会缓存在请求对象中，因此可以多次获取
 func (r *Request) GetRaw() []byte {
-    result, _ := ioutil.ReadAll(r.Body)
-    return result
+    if r.rawContent == nil {
+        r.rawContent, _ = ioutil.ReadAll(r.Body)
+    }
+    return r.rawContent
 }
 
 // 获取原始json请求输入字符串，并解析为json对象
@@ -164,18 +172,12 @@ func (r *Request) IsAjaxRequest() bool {
     return strings.EqualFold(r.Header.Get("X-Requested-With"), "XMLHttpRequest")
 }
 
-// 获取请求的客户端IP地址
+// 获取请求的客户端IP地址，只有当直连地址为可信代理(TrustedProxies)时才会使用X-Forwarded-For/X-Real-IP，
+// 否则返回直连地址，防止客户端伪造Header；部署在反向代理之后时需要将代理地址设置为可信代理。
 func (r *Request) GetClientIp() string {
     ip := r.clientIp.Val()
     if len(ip) == 0 {
-        if ip = r.Header.Get("X-Real-IP"); ip == "" {
-            array, _ := gregex.MatchString(`(.+):(\d+)`, r.RemoteAddr)
-            if len(array) > 1 {
-                ip = array[1]
-            } else {
-                ip = r.RemoteAddr
-            }
-        }
+        ip = r.getTrustedClientIp()
         r.clientIp.Set(ip)
     }
     return ip
@@ -0,0 +1,52 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 请求中间件调用链.
+
+package ghttp
+
+// 中间件调用链对象，每个请求对应一个
+type Middleware struct {
+    index   int                  // 下一个需要执行的中间件索引
+    served  bool                 // 路由服务方法是否已经执行
+    items   []*handlerParsedItem // 当前请求匹配的中间件列表
+    handler *handlerItem         // 当前请求匹配的路由服务方法
+    request *Request             // 所属请求对象
+}
+
+// 执行调用链中的下一个中间件，当所有中间件执行完毕后执行路由服务方法。
+// 中间件中调用Next()之前的代码在服务方法之前执行，之后的代码在服务方法之后执行；
+// 如果当前请求已经调用了Exit()，那么不再执行后续的中间件及服务方法。
+func (m *Middleware) Next() {
+    if m.request.IsExited() {
+        return
+    }
+    if m.index < len(m.items) {
+        item := m.items[m.index]
+        m.index++
+        // 中间件匹配的路由参数不覆盖服务方法的路由参数，并且只在当前中间件调用期间有效
+        if len(item.values) > 0 {
+            oldRouterVars := m.request.routerVars
+            m.request.routerVars = make(map[string][]string)
+            for k, v := range oldRouterVars {
+                m.request.routerVars[k] = v
+            }
+            for k, v := range item.values {
+                if _, ok := m.request.routerVars[k]; !ok {
+                    m.request.routerVars[k] = v
+                }
+            }
+            defer func() {
+                m.request.routerVars = oldRouterVars
+            }()
+        }
+        item.handler.faddr(m.request)
+        return
+    }
+    if !m.served && m.handler != nil {
+        m.served = true
+        m.request.Server.callServeHandler(m.handler, m.request)
+    }
+}
@@ -0,0 +1,114 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 请求参数结构化绑定及校验.
+
+package ghttp
+
+import (
+    "errors"
+    "reflect"
+    "strings"
+    "gitee.com/johng/gf/g/util/gconv"
+    "gitee.com/johng/gf/g/util/gvalid"
+    "gitee.com/johng/gf/g/encoding/gxml"
+    "gitee.com/johng/gf/g/encoding/gjson"
+)
+
+// 将请求参数合并后映射到struct对象上，并按照struct属性的gvalid标签执行数据校验。
+// 参数来源包括：路由参数、QueryString、表单以及JSON/XML请求体(根据Content-Type判断)，
+// 同名参数按照 路由参数 -> QueryString -> 请求体 的优先级进行覆盖；
+// 属性与参数名称的映射关系同GetToStruct，支持params及gconv标签，请求体中嵌套的对象及对象数组映射到struct(数组)属性。
+// 校验失败时返回的错误类型为gvalid.Error，请求体解析失败或者参数值无法转换为属性类型时返回普通的error。
+func (r *Request) Parse(pointer interface{}) error {
+    if rv := reflect.ValueOf(pointer); rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+        return errors.New("parse target should be a pointer to struct")
+    }
+    params, err := r.getParseParams()
+    if err != nil {
+        return err
+    }
+    // 使用严格映射，参数值无法转换为属性类型时返回错误，嵌套的JSON对象/数组映射到struct属性
+    if err := gconv.MapToStructStrict(params, pointer, r.getStructParamsTagMap(pointer)); err != nil {
+        return err
+    }
+    // 注意gvalid.Error为map类型，直接返回nil的gvalid.Error会导致返回的error不为nil
+    if e := gvalid.CheckStruct(pointer, nil); e != nil {
+        return e
+    }
+    return nil
+}
+
+// 获取合并后的请求参数，单个值的参数为string类型，多个值的参数为[]string类型，请求体中的参数保持解析后的类型
+func (r *Request) getParseParams() (map[string]interface{}, error) {
+    params := make(map[string]interface{})
+    body, err := r.getBodyParams()
+    if err != nil {
+        return nil, err
+    }
+    for k, v := range body {
+        params[k] = v
+    }
+    r.initGet()
+    for k, v := range r.queryVars {
+        params[k] = formValue(v)
+    }
+    for k, v := range r.routerVars {
+        params[k] = formValue(v)
+    }
+    return params, nil
+}
+
+// 解析请求体参数，支持JSON、XML及表单格式
+func (r *Request) getBodyParams() (map[string]interface{}, error) {
+    contentType := strings.ToLower(r.Header.Get("Content-Type"))
+    switch {
+        case strings.Contains(contentType, "json"):
+            raw := r.GetRaw()
+            if len(raw) == 0 {
+                return nil, nil
+            }
+            params := make(map[string]interface{})
+            if err := gjson.DecodeTo(raw, &params); err != nil {
+                return nil, errors.New("invalid json body: " + err.Error())
+            }
+            return params, nil
+
+        case strings.Contains(contentType, "xml"):
+            raw := r.GetRaw()
+            if len(raw) == 0 {
+                return nil, nil
+            }
+            params, err := gxml.Decode(raw)
+            if err != nil {
+                return nil, errors.New("invalid xml body: " + err.Error())
+            }
+            // XML只有一个根节点，参数为根节点下的子节点
+            if len(params) == 1 {
+                for _, v := range params {
+                    if m, ok := v.(map[string]interface{}); ok {
+                        return m, nil
+                    }
+                }
+            }
+            return params, nil
+
+        default:
+            r.initPost()
+            params := make(map[string]interface{})
+            for k, v := range r.PostForm {
+                params[k] = formValue(v)
+            }
+            return params, nil
+    }
+}
+
+// 表单参数值转换，只有一个值时返回string，否则返回[]string
+func formValue(v []string) interface{} {
+    if len(v) == 1 {
+        return v[0]
+    }
+    return v
+}
@@ -7,6 +7,7 @@
 package ghttp
 
 import (
+    "net/http"
     "gitee.com/johng/gf/g/util/gconv"
 )
 
@@ -15,8 +16,15 @@ func (r *Request) initPost() {
     if !r.parsedPost.Val() {
         // 快速保存，尽量避免并发问题
         r.parsedPost.Set(true)
-        // MultiMedia表单请求解析允许最大使用内存：1GB
-        r.ParseMultipartForm(1024*1024*1024)
+        // 设置了单个上传文件大小限制时，在读取请求体的同时检查文件大小，超过限制时立即停止解析
+        closeBody := r.limitUploadFileSize()
+        // MultiMedia表单请求解析，上传文件内容超过FormParsingMemory的部分会写入临时文件
+        if err := r.ParseMultipartForm(r.Server.config.FormParsingMemory); err != nil && err != http.ErrNotMultipart {
+            r.parsePostErr = err
+        }
+        if closeBody != nil {
+            closeBody()
+        }
     }
 }
 
@@ -0,0 +1,178 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 请求ID及链路追踪上下文(W3C Trace Context).
+
+package ghttp
+
+import (
+    "strings"
+    "context"
+    "net/http"
+    "crypto/rand"
+    "encoding/hex"
+    "gitee.com/johng/gf/g/os/glog"
+)
+
+const (
+    gHEADER_REQUEST_ID     = "X-Request-Id" // 请求ID的Header名称
+    gHEADER_TRACE_PARENT   = "traceparent"  // W3C链路追踪上下文Header名称
+    gREQUEST_ID_MAX_LENGTH = 128            // 客户端提交的请求ID最大长度
+    gTRACE_VERSION         = "00"           // traceparent版本号
+    gTRACE_FLAGS_DEFAULT   = "01"           // 默认的trace-flags(sampled)
+)
+
+// 请求上下文中链路追踪信息的键名类型
+type traceContextKey struct{}
+
+// 请求链路追踪信息
+type requestTrace struct {
+    requestId string       // 请求ID
+    traceId   string       // 链路ID(32位十六进制)
+    spanId    string       // 当前请求的span ID(16位十六进制，每次请求重新生成)
+    parentId  string       // 上游的span ID(没有上游时为空)
+    flags     string       // trace-flags
+    logger    *glog.Logger // 带有请求ID前缀的日志对象(懒创建)
+}
+
+// 初始化请求ID及链路追踪上下文：优先使用客户端提交的X-Request-Id及traceparent，
+// 不存在或者格式不合法时自动生成，请求ID没有提交时与链路ID相同，并通过X-Request-Id返回给客户端；
+// 链路追踪信息及"[请求ID]"日志前缀同时保存到r.Context()中，以便通过glog.Ctx及Client.WithContext传递。
+func (r *Request) initTrace() {
+    trace := &requestTrace {
+        spanId : newTraceId(8),
+        flags  : gTRACE_FLAGS_DEFAULT,
+    }
+    if traceId, parentId, flags, ok := parseTraceParent(r.Header.Get(gHEADER_TRACE_PARENT)); ok {
+        trace.traceId  = traceId
+        trace.parentId = parentId
+        trace.flags    = flags
+    } else {
+        trace.traceId  = newTraceId(16)
+    }
+    if id := r.Header.Get(gHEADER_REQUEST_ID); isValidRequestId(id) {
+        trace.requestId = id
+    } else {
+        trace.requestId = trace.traceId
+    }
+    r.trace = trace
+    r.Response.Header().Set(gHEADER_REQUEST_ID, trace.requestId)
+    ctx := glog.WithPrefix(r.Context(), "[" + trace.requestId + "]")
+    r.Request = *r.Request.WithContext(context.WithValue(ctx, traceContextKey{}, trace))
+}
+
+// 获取请求ID
+func (r *Request) GetRequestId() string {
+    return r.trace.requestId
+}
+
+// 获取链路ID
+func (r *Request) GetTraceId() string {
+    return r.trace.traceId
+}
+
+// 获取当前请求的span ID
+func (r *Request) GetSpanId() string {
+    return r.trace.spanId
+}
+
+// 获取向下游传递的traceparent值(以当前请求的span作为父级)
+func (r *Request) GetTraceParent() string {
+    return r.trace.traceParent()
+}
+
+// 向下游传递的traceparent值
+func (t *requestTrace) traceParent() string {
+    return gTRACE_VERSION + "-" + t.traceId + "-" + t.spanId + "-" + t.flags
+}
+
+// 获取带有请求ID前缀的日志对象，以便与访问日志及错误日志关联，
+// 等同于glog.Ctx(r.Context())，需要传递到其他方法中时也可以直接传递r.Context()
+func (r *Request) Log() *glog.Logger {
+    if r.trace.logger == nil {
+        r.trace.logger = glog.Ctx(r.Context())
+    }
+    return r.trace.logger
+}
+
+// 创建HTTP客户端，该客户端发起的请求会自动携带当前请求的X-Request-Id及traceparent，
+// 与Client.WithContext(r.Context())不同，请求结束后仍然可以使用(例如在异步goroutine中)
+func (r *Request) Client() *Client {
+    c := NewClient()
+    c.SetTrace(r)
+    return c
+}
+
+// 设置客户端请求需要传递的请求ID及链路追踪上下文
+func (c *Client) SetTrace(r *Request) {
+    c.SetHeader(gHEADER_REQUEST_ID,   r.GetRequestId())
+    c.SetHeader(gHEADER_TRACE_PARENT, r.GetTraceParent())
+}
+
+// 客户端请求的上下文来自Server请求(Client.WithContext(r.Context()))并且没有自定义请求ID及traceparent时，
+// 传递该请求的链路追踪上下文
+func setContextTrace(req *http.Request) {
+    trace, ok := req.Context().Value(traceContextKey{}).(*requestTrace)
+    if !ok {
+        return
+    }
+    if req.Header.Get(gHEADER_REQUEST_ID) == "" {
+        req.Header.Set(gHEADER_REQUEST_ID, trace.requestId)
+    }
+    if req.Header.Get(gHEADER_TRACE_PARENT) == "" {
+        req.Header.Set(gHEADER_TRACE_PARENT, trace.traceParent())
+    }
+}
+
+// 解析traceparent，格式：version-traceid-parentid-flags，如：00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func parseTraceParent(value string) (traceId, parentId, flags string, ok bool) {
+    array := strings.Split(strings.TrimSpace(value), "-")
+    if len(array) < 4 || len(array[0]) != 2 || array[0] == "ff" || (array[0] == gTRACE_VERSION && len(array) != 4) {
+        return "", "", "", false
+    }
+    if !isLowerHex(array[0]) || !isLowerHex(array[1], 32) || !isLowerHex(array[2], 16) || !isLowerHex(array[3], 2) {
+        return "", "", "", false
+    }
+    // 全0的trace-id及parent-id为非法值
+    if strings.Trim(array[1], "0") == "" || strings.Trim(array[2], "0") == "" {
+        return "", "", "", false
+    }
+    return array[1], array[2], array[3], true
+}
+
+// 判断是否为小写十六进制字符串，可指定长度
+func isLowerHex(s string, length...int) bool {
+    if len(length) > 0 && len(s) != length[0] {
+        return false
+    }
+    for i := 0; i < len(s); i++ {
+        if !(s[i] >= '0' && s[i] <= '9') && !(s[i] >= 'a' && s[i] <= 'f') {
+            return false
+        }
+    }
+    return len(s) > 0
+}
+
+// 判断客户端提交的请求ID是否合法(限制长度及可见ASCII字符，防止日志注入)
+func isValidRequestId(id string) bool {
+    if id == "" || len(id) > gREQUEST_ID_MAX_LENGTH {
+        return false
+    }
+    for i := 0; i < len(id); i++ {
+        if id[i] <= ' ' || id[i] > '~' || id[i] == '"' {
+            return false
+        }
+    }
+    return true
+}
+
+// 生成指定字节数的随机十六进制ID
+func newTraceId(size int) string {
+    b := make([]byte, size)
+    if _, err := rand.Read(b); err != nil {
+        panic(err)
+    }
+    return hex.EncodeToString(b)
+}
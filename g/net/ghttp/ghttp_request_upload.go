@@ -0,0 +1,166 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 上传文件处理.
+
+package ghttp
+
+import (
+    "io"
+    "os"
+    "errors"
+    "strconv"
+    "mime"
+    "strings"
+    "net/http"
+    "path/filepath"
+    "mime/multipart"
+    "gitee.com/johng/gf/g/os/gfile"
+    "gitee.com/johng/gf/g/os/gtime"
+    "gitee.com/johng/gf/g/util/grand"
+)
+
+// 上传文件对象
+type UploadFile struct {
+    *multipart.FileHeader        // 底层上传文件信息(Filename/Size/Header)
+    contentType           string // 根据文件内容探测的MIME类型(懒探测)
+}
+
+// 获取指定表单字段的上传文件，同名字段存在多个文件时返回第一个，文件不存在时返回nil。
+// 当上传内容超过ClientMaxBodySize或者文件大小超过UploadMaxFileSize时返回错误(在读取请求体时检查)。
+func (r *Request) GetUploadFile(name string) (*UploadFile, error) {
+    files, err := r.GetUploadFiles(name)
+    if err != nil || len(files) == 0 {
+        return nil, err
+    }
+    return files[0], nil
+}
+
+// 获取指定表单字段的所有上传文件，文件不存在时返回空数组
+func (r *Request) GetUploadFiles(name string) ([]*UploadFile, error) {
+    r.initPost()
+    if r.parsePostErr != nil {
+        return nil, r.parsePostErr
+    }
+    if r.MultipartForm == nil {
+        return nil, nil
+    }
+    headers := r.MultipartForm.File[name]
+    files   := make([]*UploadFile, 0, len(headers))
+    for _, header := range headers {
+        files = append(files, &UploadFile{ FileHeader : header })
+    }
+    return files, nil
+}
+
+// 设置了UploadMaxFileSize时，将请求体替换为逐个检查文件大小的multipart数据流：
+// 异步读取原始请求体中的每个part，文件part最多读取UploadMaxFileSize+1字节，超过限制时以错误结束数据流，
+// 表单解析随即失败，超出限制的文件内容不会被读取到内存或者临时文件中。
+// 返回的方法需要在表单解析结束后调用，用以结束异步读取；不需要限制时返回nil。
+func (r *Request) limitUploadFileSize() func() {
+    maxSize := r.Server.config.UploadMaxFileSize
+    if maxSize <= 0 || r.Body == nil {
+        return nil
+    }
+    mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+    if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+        return nil
+    }
+    reader := multipart.NewReader(r.Body, params["boundary"])
+    pr, pw := io.Pipe()
+    go func() {
+        writer := multipart.NewWriter(pw)
+        if err := writer.SetBoundary(params["boundary"]); err != nil {
+            pw.CloseWithError(err)
+            return
+        }
+        for {
+            part, err := reader.NextPart()
+            if err == io.EOF {
+                break
+            }
+            if err != nil {
+                pw.CloseWithError(err)
+                return
+            }
+            dst, err := writer.CreatePart(part.Header)
+            if err != nil {
+                pw.CloseWithError(err)
+                return
+            }
+            if part.FileName() == "" {
+                _, err = io.Copy(dst, part)
+            } else {
+                var n int64
+                if n, err = io.Copy(dst, io.LimitReader(part, maxSize + 1)); err == nil && n > maxSize {
+                    err = errors.New(`upload file "` + part.FileName() + `" exceeds the max size ` + strconv.FormatInt(maxSize, 10) + ` bytes`)
+                }
+            }
+            if err != nil {
+                pw.CloseWithError(err)
+                return
+            }
+        }
+        pw.CloseWithError(writer.Close())
+    }()
+    r.Body = pr
+    return func() {
+        pr.Close()
+    }
+}
+
+// 获取上传文件的原始文件名(已去掉客户端路径)
+func (f *UploadFile) Name() string {
+    return filepath.Base(strings.Replace(f.Filename, "\\", "/", -1))
+}
+
+// 获取根据文件内容探测的MIME类型，与客户端提交的Content-Type无关，无法识别时返回application/octet-stream
+func (f *UploadFile) ContentType() string {
+    if f.contentType != "" {
+        return f.contentType
+    }
+    file, err := f.Open()
+    if err != nil {
+        return "application/octet-stream"
+    }
+    defer file.Close()
+    buffer := make([]byte, 512)
+    n, _   := io.ReadFull(file, buffer)
+    f.contentType = http.DetectContentType(buffer[:n])
+    return f.contentType
+}
+
+// 将上传文件保存到指定目录下，目录不存在时会自动创建，返回保存后的文件名称(不包含目录)。
+// randomName为true时使用随机文件名保存(保留原始文件扩展名)，否则使用原始文件名保存(同名文件将会被覆盖)。
+func (f *UploadFile) Save(dir string, randomName...bool) (string, error) {
+    if !gfile.Exists(dir) {
+        if err := gfile.Mkdir(dir); err != nil {
+            return "", err
+        }
+    } else if !gfile.IsDir(dir) {
+        return "", errors.New(`"` + dir + `" is not a directory`)
+    }
+    name := f.Name()
+    if len(randomName) > 0 && randomName[0] {
+        name = strings.ToLower(strconv.FormatInt(gtime.Nanosecond(), 36) + grand.RandStr(6)) + strings.ToLower(gfile.Ext(name))
+    }
+    if name == "" || name == "." || name == "/" || name == ".." {
+        return "", errors.New(`invalid upload file name "` + f.Filename + `"`)
+    }
+    src, err := f.Open()
+    if err != nil {
+        return "", err
+    }
+    defer src.Close()
+    dst, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+    if err != nil {
+        return "", err
+    }
+    defer dst.Close()
+    if _, err := io.Copy(dst, src); err != nil {
+        return "", err
+    }
+    return name, nil
+}
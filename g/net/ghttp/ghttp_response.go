@@ -23,6 +23,7 @@ type Response struct {
     Server  *Server         // 所属Web Server
     Writer  *ResponseWriter // ResponseWriter的别名
     request *Request        // 关联的Request请求对象
+    sse     *SSE            // Server-Sent Events输出对象(懒创建)
 }
 
 // 创建一个ghttp.Response对象指针
@@ -206,13 +207,40 @@ func (r *Response) ClearBuffer() {
 
 // 输出缓冲区数据到客户端
 func (r *Response) OutputBuffer() {
+    if r.sse != nil {
+        r.sse.close()
+    }
     r.Header().Set("Server", r.Server.config.ServerAgent)
     //r.handleGzip()
     r.Writer.OutputBuffer()
 }
 
+// 将缓冲区数据立即输出到客户端，并进入流式输出模式(chunked)，适用于长轮询、大文件导出等场景，
+// 首次调用时会输出Header及Cookie，此后设置的Header及Cookie将不再生效；客户端断开时返回错误。
+func (r *Response) Flush() error {
+    if !r.streaming {
+        r.Header().Set("Server", r.Server.config.ServerAgent)
+        // 没有设置Content-Type时根据已有内容探测，防止底层根据压缩后的内容探测
+        if r.Header().Get("Content-Type") == "" && len(r.buffer) > 0 {
+            r.Header().Set("Content-Type", http.DetectContentType(r.buffer))
+        }
+        r.request.Cookie.Output()
+        r.handleStreamGzip()
+        r.streaming = true
+    }
+    return r.Writer.flush()
+}
+
+// 是否已进入流式输出模式
+func (r *Response) IsStreaming() bool {
+    return r.streaming
+}
+
 // 获取输出到客户端的数据大小
 func (r *Response) ContentSize() int {
+    if r.streaming {
+        return r.flushed + r.BufferLength()
+    }
     if r.length > 0 {
         return r.length
     }
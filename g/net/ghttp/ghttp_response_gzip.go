@@ -9,6 +9,7 @@ package ghttp
 
 import (
     "mime"
+    "compress/gzip"
     "gitee.com/johng/gf/g/os/gfile"
     "gitee.com/johng/gf/g/encoding/gcompress"
     "strings"
@@ -54,25 +55,41 @@ var defaultGzipContentTypes = []string{
 
 // 返回内容gzip检查处理
 func (r *Response) handleGzip() {
-    // 如果客户端支持gzip压缩，并且服务端设置开启gzip压缩特性，那么执行压缩
-    encoding := r.request.Header.Get("Accept-Encoding")
-    if encoding != "" && strings.Contains(encoding, "gzip") {
-        mimeType := ""
-        ext := gfile.Ext(r.request.URL.Path)
-        if ext != "" {
-            mimeType = strings.Split(mime.TypeByExtension(ext), ";")[0]
-        }
-        if mimeType == "" {
-            contentType := r.Header().Get("Content-Type")
-            if contentType != "" {
-                mimeType = strings.Split(contentType, ";")[0]
-            }
-        }
+    if r.isGzipAllowed() {
+        r.SetBuffer(gcompress.Gzip(r.buffer))
+        r.Header().Set("Content-Length",   gconv.String(len(r.buffer)))
+        r.Header().Set("Content-Encoding", "gzip")
+    }
+}
 
-        if _, ok := r.Server.gzipMimesMap[mimeType]; ok {
-            r.SetBuffer(gcompress.Gzip(r.buffer))
-            r.Header().Set("Content-Length",   gconv.String(len(r.buffer)))
-            r.Header().Set("Content-Encoding", "gzip")
+// 流式输出模式下的gzip检查处理，允许压缩时后续输出的内容将通过gzip.Writer增量压缩
+func (r *Response) handleStreamGzip() {
+    if r.Header().Get("Content-Encoding") != "" || !r.isGzipAllowed() {
+        return
+    }
+    r.Header().Del("Content-Length")
+    r.Header().Set("Content-Encoding", "gzip")
+    r.Header().Add("Vary", "Accept-Encoding")
+    r.gzip = gzip.NewWriter(r.ResponseWriter.ResponseWriter)
+}
+
+// 判断返回内容是否允许gzip压缩：客户端支持gzip压缩，并且返回内容类型在服务端设置的压缩类型中
+func (r *Response) isGzipAllowed() bool {
+    encoding := r.request.Header.Get("Accept-Encoding")
+    if encoding == "" || !strings.Contains(encoding, "gzip") {
+        return false
+    }
+    mimeType := ""
+    ext := gfile.Ext(r.request.URL.Path)
+    if ext != "" {
+        mimeType = strings.Split(mime.TypeByExtension(ext), ";")[0]
+    }
+    if mimeType == "" {
+        contentType := r.Header().Get("Content-Type")
+        if contentType != "" {
+            mimeType = strings.Split(contentType, ";")[0]
         }
     }
+    _, ok := r.Server.gzipMimesMap[mimeType]
+    return ok
 }
\ No newline at end of file
@@ -0,0 +1,157 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// Server-Sent Events.
+
+package ghttp
+
+import (
+    "sync"
+    "time"
+    "errors"
+    "strconv"
+    "strings"
+    "gitee.com/johng/gf/g/util/gconv"
+    "gitee.com/johng/gf/g/encoding/gparser"
+)
+
+// SSE事件
+type SSEEvent struct {
+    Id    string      // 事件ID(id字段)，客户端重连时通过Last-Event-ID请求Header提交
+    Event string      // 事件名称(event字段)，为空时客户端触发message事件
+    Data  interface{} // 事件数据(data字段)，string/[]byte原样输出，其他类型转换为JSON
+    Retry int         // 客户端重连间隔(retry字段，毫秒)，为0时不输出
+}
+
+// Server-Sent Events输出对象
+type SSE struct {
+    mu       sync.Mutex    // 输出互斥锁(心跳与事件可能并发输出)
+    response *Response     // 所属Response对象
+    closed   bool          // 是否已关闭(请求处理完成)
+    stop     chan struct{} // 关闭通知(用于停止心跳)
+}
+
+// SSE输出对象已关闭的错误
+var errSSEClosed = errors.New("sse stream is closed")
+
+// 获取Server-Sent Events输出对象，首次调用时设置相关Header并进入流式输出模式，
+// 事件需要在请求处理方法返回前输出，可通过Done判断客户端是否已断开连接。
+func (r *Response) SSE() *SSE {
+    if r.sse == nil {
+        r.Header().Set("Content-Type",      "text/event-stream; charset=utf-8")
+        r.Header().Set("Cache-Control",     "no-cache")
+        r.Header().Set("Connection",        "keep-alive")
+        // 禁止nginx等反向代理缓冲
+        r.Header().Set("X-Accel-Buffering", "no")
+        r.sse = &SSE {
+            response : r,
+            stop     : make(chan struct{}),
+        }
+        r.Flush()
+    }
+    return r.sse
+}
+
+// 输出事件
+func (s *SSE) Send(event SSEEvent) error {
+    buffer := make([]byte, 0, 64)
+    if event.Id != "" {
+        buffer = append(buffer, "id: " + sseFieldValue(event.Id) + "\n"...)
+    }
+    if event.Event != "" {
+        buffer = append(buffer, "event: " + sseFieldValue(event.Event) + "\n"...)
+    }
+    if event.Retry > 0 {
+        buffer = append(buffer, "retry: " + strconv.Itoa(event.Retry) + "\n"...)
+    }
+    data := ""
+    switch v := event.Data.(type) {
+        case nil:
+        case string, []byte:
+            data = gconv.String(v)
+        default:
+            b, err := gparser.VarToJson(v)
+            if err != nil {
+                return err
+            }
+            data = string(b)
+    }
+    // 多行数据需要拆分为多个data字段
+    for _, line := range strings.Split(strings.Replace(data, "\r\n", "\n", -1), "\n") {
+        buffer = append(buffer, "data: " + line + "\n"...)
+    }
+    return s.write(append(buffer, '\n'))
+}
+
+// 输出只包含数据的事件
+func (s *SSE) SendData(data interface{}) error {
+    return s.Send(SSEEvent{ Data : data })
+}
+
+// 输出注释(客户端会忽略)，常用于保持连接
+func (s *SSE) Comment(comment string) error {
+    return s.write([]byte(": " + sseFieldValue(comment) + "\n\n"))
+}
+
+// 按照给定间隔自动输出心跳注释，防止连接被中间代理超时断开，请求处理完成或者客户端断开时自动停止
+func (s *SSE) Heartbeat(interval time.Duration) {
+    go func() {
+        ticker := time.NewTicker(interval)
+        defer ticker.Stop()
+        for {
+            select {
+                case <- ticker.C:
+                    if s.Comment("ping") != nil {
+                        return
+                    }
+                case <- s.stop:
+                    return
+                case <- s.Done():
+                    return
+            }
+        }
+    }()
+}
+
+// 客户端断开连接时关闭的channel
+func (s *SSE) Done() <-chan struct{} {
+    return s.response.request.Context().Done()
+}
+
+// 获取客户端重连时提交的最后事件ID
+func (s *SSE) LastEventId() string {
+    return s.response.request.Header.Get("Last-Event-ID")
+}
+
+// 输出内容并立即发送到客户端
+func (s *SSE) write(content []byte) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.closed {
+        return errSSEClosed
+    }
+    select {
+        case <- s.Done():
+            return s.response.request.Context().Err()
+        default:
+    }
+    s.response.Write(content)
+    return s.response.Flush()
+}
+
+// 关闭输出对象(请求处理完成时调用)，此后不能再输出事件
+func (s *SSE) close() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if !s.closed {
+        s.closed = true
+        close(s.stop)
+    }
+}
+
+// 去掉字段值中的换行符，防止破坏事件格式
+func sseFieldValue(value string) string {
+    return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
@@ -10,6 +10,7 @@ package ghttp
 import (
     "gitee.com/johng/gf/g/os/gview"
     "gitee.com/johng/gf/g/frame/gins"
+    "gitee.com/johng/gf/g/util/gconv"
 )
 
 // 展示模板，可以给定模板参数，及临时的自定义模板函数
@@ -70,9 +71,12 @@ func (r *Response) buildInVars(params map[string]interface{}) map[string]interfa
 
 // 内置函数
 func (r *Response) buildInfuncs(funcmap map[string]interface{}) map[string]interface{} {
-    funcmap["get"]       = r.funcGet
-    funcmap["post"]      = r.funcPost
-    funcmap["request"]   = r.funcRequest
+    funcmap["get"]        = r.funcGet
+    funcmap["post"]       = r.funcPost
+    funcmap["request"]    = r.funcRequest
+    funcmap["url"]        = r.funcUrl
+    funcmap["csrf_token"] = r.funcCsrfToken
+    funcmap["csrf_field"] = r.funcCsrfField
     return funcmap
 }
 
@@ -89,4 +93,14 @@ func (r *Response) funcPost(key string, def...string) gview.HTML {
 // 模板内置函数: request
 func (r *Response) funcRequest(key string, def...string) gview.HTML {
     return gview.HTML(r.request.Get(key, def...))
+}
+
+// 模板内置函数: url，根据路由名称生成URL，路由参数以键值对形式依次给定，
+// 例如：{{url "user.detail" "id" 1}}
+func (r *Response) funcUrl(name string, pairs...interface{}) (string, error) {
+    params := make(map[string]interface{})
+    for i := 0; i + 1 < len(pairs); i += 2 {
+        params[gconv.String(pairs[i])] = pairs[i + 1]
+    }
+    return r.Server.URLFor(name, params)
 }
\ No newline at end of file
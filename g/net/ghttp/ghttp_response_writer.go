@@ -10,14 +10,18 @@ package ghttp
 import (
     "net/http"
     "sync"
+    "compress/gzip"
 )
 
 // 自定义的ResponseWriter，用于写入流的控制
 type ResponseWriter struct {
     http.ResponseWriter
-    mu     sync.RWMutex    // 缓冲区互斥锁
-    Status int             // http status
-    buffer []byte          // 缓冲区内容
+    mu        sync.RWMutex    // 缓冲区互斥锁
+    Status    int             // http status
+    buffer    []byte          // 缓冲区内容
+    streaming bool            // 是否已进入流式输出模式(调用过Flush)
+    gzip      *gzip.Writer    // 流式输出模式下的增量gzip压缩对象(为nil表示不压缩)
+    flushed   int             // 流式输出模式下已输出的内容长度(压缩前，byte)
 }
 
 // 覆盖父级的WriteHeader方法
@@ -32,8 +36,18 @@ func (w *ResponseWriter) WriteHeader(code int) {
     w.ResponseWriter.WriteHeader(code)
 }
 
-// 输出buffer数据到客户端
+// 输出buffer数据到客户端，流式输出模式下会结束gzip压缩流
 func (w *ResponseWriter) OutputBuffer() {
+    if w.streaming {
+        w.flush()
+        w.mu.Lock()
+        if w.gzip != nil {
+            w.gzip.Close()
+            w.gzip = nil
+        }
+        w.mu.Unlock()
+        return
+    }
     if len(w.buffer) > 0 {
         w.mu.Lock()
         w.ResponseWriter.Write(w.buffer)
@@ -41,3 +55,28 @@ func (w *ResponseWriter) OutputBuffer() {
         w.mu.Unlock()
     }
 }
+
+// 流式输出模式下将缓冲区数据输出到客户端并立即发送(chunked)，客户端断开时返回错误
+func (w *ResponseWriter) flush() error {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if len(w.buffer) > 0 {
+        var err error
+        if w.gzip != nil {
+            if _, err = w.gzip.Write(w.buffer); err == nil {
+                err = w.gzip.Flush()
+            }
+        } else {
+            _, err = w.ResponseWriter.Write(w.buffer)
+        }
+        w.flushed += len(w.buffer)
+        w.buffer   = w.buffer[:0]
+        if err != nil {
+            return err
+        }
+    }
+    if f, ok := w.ResponseWriter.(http.Flusher); ok {
+        f.Flush()
+    }
+    return nil
+}
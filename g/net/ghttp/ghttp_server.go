@@ -53,6 +53,9 @@ const (
     gROUTE_REGISTER_HANDLER    = 1
     gROUTE_REGISTER_OBJECT     = 2
     gROUTE_REGISTER_CONTROLLER = 3
+    gROUTE_REGISTER_MIDDLEWARE = 4
+    gROUTE_REGISTER_PROXY      = 5
+    gROUTE_REGISTER_REWRITE    = 6
 )
 
 // ghttp.Server结构体
@@ -71,6 +74,9 @@ type Server struct {
     serveCache       *gcache.Cache            // 服务注册路由内存缓存
     hooksCache       *gcache.Cache            // 事件回调路由内存缓存
     routesMap        map[string]string        // 已经注册的路由及对应的注册方法文件地址(用以路由重复注册判断)
+    middlewares      []*handlerItem           // 所有注册的中间件(按照注册顺序执行)
+    rnmu             sync.RWMutex             // 路由名称互斥锁
+    routeNames       map[string]*Router       // 路由名称与路由对象的映射(用于反向生成URL)
     // 自定义状态码回调
     hsmu             sync.RWMutex             // status handler互斥锁
     statusHandlerMap map[string]HandlerFunc   // 不同状态码下的注册处理方法(例如404状态时的处理方法)
@@ -79,7 +85,21 @@ type Server struct {
     // SESSION
     sessionMaxAge    *gtype.Int               // Session有效期
     sessionIdName    *gtype.String            // SessionId名称
-    sessions         *gcache.Cache            // Session内存缓存
+    sessionStorage   SessionStorage           // Session存储对象
+    // 访问控制
+    denyIps          *gtype.Interface         // 已解析的禁止访问IP规则(ipRules)
+    allowIps         *gtype.Interface         // 已解析的允许访问IP规则(ipRules)
+    trustedProxies   *gtype.Interface         // 已解析的可信代理IP规则(ipRules)
+    limiter          *serverLimiter           // 全局请求频率及并发数限制(为nil时不限制)
+    limitersMu       sync.Mutex               // 限制对象列表互斥锁
+    limiters         []*serverLimiter         // 创建的所有限制对象(Server关闭时停止其定时清理)
+    csrf             *serverCsrf              // CSRF防护(为nil时表示未开启)
+    // HTTPS证书
+    certificates     *serverCertificates      // HTTPS证书管理(默认证书及SNI域名证书)
+    metrics          *serverMetrics           // 监控指标统计(为nil时表示未开启)
+    wsHub            *WebSocketHub            // WebSocket连接管理(为nil时表示未开启)
+    wsHubMu          sync.RWMutex             // WebSocket连接管理创建锁
+    openapi          *serverOpenApi           // OpenAPI接口文档
     // 日志相关属性
     logPath          *gtype.String            // 存放日志的目录路径
     logHandler       *gtype.Interface         // 自定义日志处理回调方法
@@ -90,10 +110,12 @@ type Server struct {
     // 其他属性
     nameToUriType    *gtype.Int               // 服务注册时对象和方法名称转换为URI时的规则
     gzipMimesMap     map[string]struct{}      // 支持gzip压缩的类型
+    staticFS         []staticFSItem           // 静态文件系统(按照添加顺序检索)
 }
 
 // 路由对象
 type Router struct {
+    Name     string       // 路由名称(可选)
     Uri      string       // 注册时的pattern - uri
     Method   string       // 注册时的pattern - method
     Domain   string       // 注册时的pattern - domain
@@ -114,6 +136,10 @@ type handlerItem struct {
     finit    HandlerFunc  // 初始化请求回调方法(执行对象注册方式下有效)
     fshut    HandlerFunc  // 完成请求回调方法(执行对象注册方式下有效)
     router   *Router      // 注册时绑定的路由对象
+    rname    string       // 路由名称(可选，用于反向生成URL)
+    target   string       // 本地代理的目标路由(本地代理注册方式下有效)
+    doc      *ApiDoc      // 接口文档(可选，用于生成OpenAPI文档)
+    source   string       // 注册路由的源码位置(文件:行号)
 }
 
 // 根据特定URL.Path解析后的路由检索结果项
@@ -188,7 +214,7 @@ func GetServer(name...interface{}) (*Server) {
         serveCache       : gcache.New(),
         hooksCache       : gcache.New(),
         routesMap        : make(map[string]string),
-        sessions         : gcache.New(),
+        routeNames       : make(map[string]*Router),
         servedCount      : gtype.NewInt(),
         closeQueue       : gqueue.New(),
         accessLogger     : glog.New(),
@@ -202,6 +228,11 @@ func GetServer(name...interface{}) (*Server) {
         errorLogEnabled  : gtype.NewBool(),
         logHandler       : gtype.NewInterface(),
         nameToUriType    : gtype.NewInt(),
+        denyIps          : gtype.NewInterface(),
+        allowIps         : gtype.NewInterface(),
+        trustedProxies   : gtype.NewInterface(),
+        certificates     : newServerCertificates(),
+        openapi          : newServerOpenApi(),
         gzipMimesMap     : make(map[string]struct{}),
     }
     //s.errorLogger.SetBacktraceSkip(1)
@@ -309,10 +340,33 @@ func Wait() {
 }
 
 
+// 释放Web Server关闭后不再需要的资源(如Session存储、请求限制的定时清理及WebSocket连接管理)
+func (s *Server) releaseResources() {
+    s.closeLimiters()
+    s.wsHubMu.RLock()
+    hub := s.wsHub
+    s.wsHubMu.RUnlock()
+    if hub != nil {
+        hub.Close()
+    }
+    if closer, ok := s.sessionStorage.(SessionStorageCloser); ok {
+        if err := closer.Close(); err != nil {
+            glog.Error(err)
+        }
+    }
+}
+
 // 开启底层Web Server执行
 func (s *Server) startServer(fdMap listenerFdMap) {
     var httpsEnabled bool
+    // 默认证书加载，域名证书(SNI)在Domain.EnableHTTPS时已加载
     if len(s.config.HTTPSCertPath) > 0 && len(s.config.HTTPSKeyPath) > 0 {
+        if err := s.certificates.setDefault(s.config.HTTPSCertPath, s.config.HTTPSKeyPath); err != nil {
+            glog.Error(err)
+        }
+    }
+    // 平滑重启时即使证书加载失败，也需要接管父进程传递的HTTPS监听文件描述符
+    if !s.certificates.isEmpty() || len(fdMap["https"]) > 0 {
         // ================
         // HTTPS
         // ================
@@ -392,7 +446,7 @@ func (s *Server) startServer(fdMap listenerFdMap) {
             serverRunning.Add(1)
             err := (error)(nil)
             if server.isHttps {
-                err = server.ListenAndServeTLS(s.config.HTTPSCertPath, s.config.HTTPSKeyPath)
+                err = server.ListenAndServeTLS(s.getTLSConfig())
             } else {
                 err = server.ListenAndServe()
             }
@@ -58,6 +58,7 @@ func (p *utilAdmin) Index(r *Request) {
                 <title>gf ghttp admin</title>
             </head>
             <body>
+                <p><a href="{{$.uri}}/routes">routes</a></p>
                 <p><a href="{{$.uri}}/restart">restart</a></p>
                 <p><a href="{{$.uri}}/shutdown">shutdown</a></p>
             </body>
@@ -66,6 +67,46 @@ func (p *utilAdmin) Index(r *Request) {
     r.Response.Write(buffer)
 }
 
+// 路由表，被更高优先级路由覆盖的路由以红色标记
+func (p *utilAdmin) Routes(r *Request) {
+    if r.GetQueryString("format") == "text" {
+        r.Response.Header().Set("Content-Type", "text/plain; charset=utf-8")
+        r.Response.Write(r.Server.formatRoutes())
+        return
+    }
+    data := map[string]interface{}{
+        "name"   : r.Server.name,
+        "uri"    : strings.TrimRight(r.URL.Path, "/"),
+        "routes" : r.Server.GetRoutes(),
+    }
+    buffer, _ := gview.ParseContent(`
+            <html>
+            <head>
+                <title>gf ghttp admin - routes</title>
+                <style>
+                    body  {font-family: monospace; font-size: 13px;}
+                    table {border-collapse: collapse;}
+                    th,td {border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top;}
+                    tr.shadowed td {color: #c00;}
+                </style>
+            </head>
+            <body>
+                <p>server: {{$.name}}, <a href="{{$.uri}}?format=text">text</a></p>
+                <table>
+                    <tr><th>Domain</th><th>Method</th><th>Pattern</th><th>Name</th><th>Priority</th><th>Type</th><th>Hooks</th><th>Source</th><th>Shadowed By</th></tr>
+                    {{range $.routes}}
+                    <tr{{if .ShadowedBy}} class="shadowed"{{end}}>
+                        <td>{{.Domain}}</td><td>{{.Method}}</td><td>{{.Pattern}}</td><td>{{.Name}}</td><td>{{.Priority}}</td><td>{{.Type}}</td>
+                        <td>{{range .Hooks}}{{.}}<br/>{{end}}</td><td>{{.Source}}</td><td>{{.ShadowedBy}}</td>
+                    </tr>
+                    {{end}}
+                </table>
+            </body>
+            </html>
+    `, data)
+    r.Response.Write(buffer)
+}
+
 // 服务重启
 func (p *utilAdmin) Restart(r *Request) {
     var err error = nil
@@ -291,6 +332,7 @@ func gracefulShutdownWebServers() {
             for _, s := range v.(*Server).servers {
                 s.shutdown()
             }
+            v.(*Server).releaseResources()
         }
     })
 }
@@ -303,6 +345,7 @@ func forcedlyCloseWebServers() {
             for _, s := range v.(*Server).servers {
                 s.close()
             }
+            v.(*Server).releaseResources()
         }
     })
 }
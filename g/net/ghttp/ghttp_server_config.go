@@ -11,18 +11,20 @@ import (
     "time"
     "net/http"
     "strconv"
+    "crypto/tls"
     "strings"
     "gitee.com/johng/gf/g/os/glog"
     "gitee.com/johng/gf/g/os/gfile"
 )
 
 const (
-    gDEFAULT_HTTP_ADDR        = ":80"  // 默认HTTP监听地址
-    gDEFAULT_HTTPS_ADDR       = ":443" // 默认HTTPS监听地址
-    NAME_TO_URI_TYPE_DEFAULT  = 0      // 服务注册时对象和方法名称转换为URI时，全部转为小写，单词以'-'连接符号连接
-    NAME_TO_URI_TYPE_FULLNAME = 1      // 不处理名称，以原有名称构建成URI
-    NAME_TO_URI_TYPE_ALLLOWER = 2      // 仅转为小写，单词间不使用连接符号
-    NAME_TO_URI_TYPE_CAMEL    = 3      // 采用驼峰命名方式
+    gDEFAULT_HTTP_ADDR        = ":80"    // 默认HTTP监听地址
+    gDEFAULT_HTTPS_ADDR       = ":443"   // 默认HTTPS监听地址
+    gDEFAULT_FORM_MEMORY      = 32 << 20 // 默认的表单解析内存大小(32MB)
+    NAME_TO_URI_TYPE_DEFAULT  = 0        // 服务注册时对象和方法名称转换为URI时，全部转为小写，单词以'-'连接符号连接
+    NAME_TO_URI_TYPE_FULLNAME = 1        // 不处理名称，以原有名称构建成URI
+    NAME_TO_URI_TYPE_ALLLOWER = 2        // 仅转为小写，单词间不使用连接符号
+    NAME_TO_URI_TYPE_CAMEL    = 3        // 采用驼峰命名方式
 )
 
 // HTTP Server 设置结构体，静态配置
@@ -32,16 +34,25 @@ type ServerConfig struct {
     HTTPSAddr        string        // HTTPS服务监听地址(支持多个地址，使用","号分隔)
     HTTPSCertPath    string        // HTTPS证书文件路径
     HTTPSKeyPath     string        // HTTPS签名文件路径
+    TLSConfig        *tls.Config   // HTTPS服务自定义TLS配置(没有设置证书时使用HTTPSCertPath/HTTPSKeyPath及域名证书)
+    HTTP2Enabled     bool          // HTTPS服务是否开启HTTP/2(默认关闭)
+    H2CEnabled       bool          // HTTP服务是否开启不加密的HTTP/2(h2c)，建议仅用于内部服务之间的通信(需要Go 1.24及以上版本)
     Handler          http.Handler  // 默认的处理函数
     ReadTimeout      time.Duration
     WriteTimeout     time.Duration
     IdleTimeout      time.Duration
     MaxHeaderBytes   int           // 最大的header长度
+    // 请求体及上传文件配置
+    ClientMaxBodySize int64        // 客户端请求体的最大大小(byte)，超过时返回413状态码，为0时表示不限制
+    UploadMaxFileSize int64        // 单个上传文件的最大大小(byte)，为0时表示不限制
+    FormParsingMemory int64        // 表单解析时最多使用的内存大小(byte)，超过部分的上传文件内容写入临时文件
     // 静态文件配置
     IndexFiles       []string      // 默认访问的文件列表
     IndexFolder      bool          // 如果访问目录是否显示目录列表
     ServerAgent      string        // server agent
     ServerRoot       string        // 服务器服务的本地目录根路径
+    StaticCaches     map[string]StaticCacheConfig // 按照URI前缀设置的静态文件缓存配置(最长前缀匹配)
+    StaticPrecompressed bool       // 是否优先输出预压缩的静态文件(同目录下的.br/.gz文件)
     // 日志配置
     LogPath          string       // 存放日志的目录路径
     LogHandler       func(r *Request, error ... interface{})  // 自定义日志处理回调方法
@@ -52,11 +63,15 @@ type ServerConfig struct {
     // SESSION
     SessionMaxAge    int          // Session有效期
     SessionIdName    string       // SessionId名称
+    SessionStorage   SessionStorage // Session存储对象，默认为内存存储
     // 其他设置
     NameToUriType    int          // 服务注册时对象和方法名称转换为URI时的规则
     // ip访问控制
-    DenyIps          []string     // 不允许访问的ip列表，支持ip前缀过滤，如: 10 将不允许10开头的ip访问
-    AllowIps         []string     // 仅允许访问的ip列表，支持ip前缀过滤，如: 10 将仅允许10开头的ip访问
+    DenyIps          []string     // 不允许访问的ip列表，支持精确IP、CIDR(如: 10.0.0.0/8)、通配符(如: 192.168.*.*)及前缀(如: 10)，支持IPv6
+    AllowIps         []string     // 仅允许访问的ip列表，规则格式同DenyIps
+    TrustedProxies   []string     // 可信代理ip列表，规则格式同DenyIps，只有可信代理转发的X-Forwarded-For/X-Real-IP才会用于获取客户端IP
+    // 请求限制
+    Limiter          *LimiterConfig // 全局请求频率及并发数限制配置，为nil时不限制
     // 路由访问控制
     DenyRoutes       []string     // 不允许访问的路由规则列表
     // Gzip压缩文件类型
@@ -72,6 +87,8 @@ var defaultServerConfig = ServerConfig {
     WriteTimeout     : 60 * time.Second,
     IdleTimeout      : 60 * time.Second,
     MaxHeaderBytes   : 1024,
+    HTTP2Enabled     : false,
+    FormParsingMemory : gDEFAULT_FORM_MEMORY,
     IndexFiles       : []string{"index.html", "index.htm"},
     IndexFolder      : false,
     ServerAgent      : "gf",
@@ -112,6 +129,9 @@ func (s *Server)SetConfig(c ServerConfig) {
     if s.config.ServerAgent == "" {
         s.SetServerAgent(defaultServerConfig.ServerAgent)
     }
+    if s.config.FormParsingMemory <= 0 {
+        s.SetFormParsingMemory(defaultServerConfig.FormParsingMemory)
+    }
 
     // **********************
     // 可动态设置的配置处理
@@ -130,6 +150,21 @@ func (s *Server)SetConfig(c ServerConfig) {
     if len(c.SessionIdName) > 0 {
         s.SetSessionIdName(c.SessionIdName)
     }
+    if c.SessionStorage != nil {
+        s.SetSessionStorage(c.SessionStorage)
+    } else if s.sessionStorage == nil {
+        s.SetSessionStorage(NewSessionStorageMemory())
+    } else {
+        s.config.SessionStorage = s.sessionStorage
+    }
+    s.SetDenyIps(c.DenyIps)
+    s.SetAllowIps(c.AllowIps)
+    s.SetTrustedProxies(c.TrustedProxies)
+    if c.Limiter != nil {
+        if err := s.SetLimiter(*c.Limiter); err != nil {
+            glog.Error(err)
+        }
+    }
     s.SetNameToUriType(c.NameToUriType)
 }
 
@@ -182,7 +217,7 @@ func (s *Server)SetHTTPSPort(port...int) {
     }
 }
 
-// 开启HTTPS支持，但是必须提供Cert和Key文件
+// 开启HTTPS支持，但是必须提供Cert和Key文件，证书文件变更时会自动重新加载
 func (s *Server)EnableHTTPS(certFile, keyFile string) {
     if s.Status() == SERVER_STATUS_RUNNING {
         glog.Error("cannot be changed while running")
@@ -192,6 +227,31 @@ func (s *Server)EnableHTTPS(certFile, keyFile string) {
     
 }
 
+// 设置HTTPS服务的自定义TLS配置
+func (s *Server)SetTLSConfig(config *tls.Config) {
+    if s.Status() == SERVER_STATUS_RUNNING {
+        glog.Error("cannot be changed while running")
+    }
+    s.config.TLSConfig = config
+}
+
+// 设置HTTPS服务是否开启HTTP/2(默认关闭)
+func (s *Server)SetHTTP2Enabled(enabled bool) {
+    if s.Status() == SERVER_STATUS_RUNNING {
+        glog.Error("cannot be changed while running")
+    }
+    s.config.HTTP2Enabled = enabled
+}
+
+// 设置HTTP服务是否开启不加密的HTTP/2(h2c)，建议仅用于内部服务之间的通信，
+// 需要Go 1.24及以上版本，低版本下该设置无效并输出错误日志
+func (s *Server)SetH2CEnabled(enabled bool) {
+    if s.Status() == SERVER_STATUS_RUNNING {
+        glog.Error("cannot be changed while running")
+    }
+    s.config.H2CEnabled = enabled
+}
+
 // 设置http server参数 - ReadTimeout
 func (s *Server)SetReadTimeout(t time.Duration) {
     if s.Status() == SERVER_STATUS_RUNNING {
@@ -228,6 +288,30 @@ func (s *Server)SetMaxHeaderBytes(b int) {
     
 }
 
+// 设置http server参数 - ClientMaxBodySize，客户端请求体的最大大小(byte)
+func (s *Server)SetClientMaxBodySize(size int64) {
+    if s.Status() == SERVER_STATUS_RUNNING {
+        glog.Error("cannot be changed while running")
+    }
+    s.config.ClientMaxBodySize = size
+}
+
+// 设置http server参数 - UploadMaxFileSize，单个上传文件的最大大小(byte)
+func (s *Server)SetUploadMaxFileSize(size int64) {
+    if s.Status() == SERVER_STATUS_RUNNING {
+        glog.Error("cannot be changed while running")
+    }
+    s.config.UploadMaxFileSize = size
+}
+
+// 设置http server参数 - FormParsingMemory，表单解析时最多使用的内存大小(byte)
+func (s *Server)SetFormParsingMemory(size int64) {
+    if s.Status() == SERVER_STATUS_RUNNING {
+        glog.Error("cannot be changed while running")
+    }
+    s.config.FormParsingMemory = size
+}
+
 // 设置http server参数 - IndexFiles，默认展示文件，如：index.html, index.htm
 func (s *Server)SetIndexFiles(index []string) {
     if s.Status() == SERVER_STATUS_RUNNING {
@@ -268,18 +352,41 @@ func (s *Server)SetServerRoot(root string) {
     s.config.ServerRoot = strings.TrimRight(path, string(gfile.Separator))
 }
 
-func (s *Server) SetDenyIps(ips []string) {
+// 设置指定URI前缀的静态文件缓存配置，如：SetStaticCache("/assets", StaticCacheConfig{CacheControl : "public, max-age=31536000"})
+func (s *Server)SetStaticCache(prefix string, config StaticCacheConfig) {
     if s.Status() == SERVER_STATUS_RUNNING {
         glog.Error("cannot be changed while running")
     }
-    s.config.DenyIps = ips
+    if s.config.StaticCaches == nil {
+        s.config.StaticCaches = make(map[string]StaticCacheConfig)
+    }
+    s.config.StaticCaches[prefix] = config
 }
 
-func (s *Server) SetAllowIps(ips []string) {
+// 设置是否优先输出预压缩的静态文件，开启后根据客户端的Accept-Encoding优先输出同目录下的.br/.gz文件
+func (s *Server)SetStaticPrecompressed(enabled bool) {
     if s.Status() == SERVER_STATUS_RUNNING {
         glog.Error("cannot be changed while running")
     }
+    s.config.StaticPrecompressed = enabled
+}
+
+// 设置禁止访问的IP规则列表(可在Server运行时动态设置)
+func (s *Server) SetDenyIps(ips []string) {
+    s.config.DenyIps = ips
+    s.denyIps.Set(mustParseIpRules(ips))
+}
+
+// 设置仅允许访问的IP规则列表(可在Server运行时动态设置)
+func (s *Server) SetAllowIps(ips []string) {
     s.config.AllowIps = ips
+    s.allowIps.Set(mustParseIpRules(ips))
+}
+
+// 设置可信代理IP规则列表(可在Server运行时动态设置)
+func (s *Server) SetTrustedProxies(ips []string) {
+    s.config.TrustedProxies = ips
+    s.trustedProxies.Set(mustParseIpRules(ips))
 }
 
 func (s *Server) SetDenyRoutes(routes []string) {
@@ -311,6 +418,15 @@ func (s *Server)SetSessionIdName(name string) {
     s.sessionIdName.Set(name)
 }
 
+// 设置http server参数 - SessionStorage
+func (s *Server)SetSessionStorage(storage SessionStorage) {
+    if s.Status() == SERVER_STATUS_RUNNING {
+        glog.Error("cannot be changed while running")
+    }
+    s.config.SessionStorage = storage
+    s.sessionStorage        = storage
+}
+
 // 设置日志目录
 func (s *Server)SetLogPath(path string) {
     if len(path) == 0 {
@@ -352,6 +468,16 @@ func (s *Server) AddSearchPath(path string) error {
     return s.paths.Add(path)
 }
 
+// 添加静态文件系统，将URI前缀映射到给定的文件系统(如编译到二进制中的资源包)，
+// 静态文件检索时优先检索本地目录(ServerRoot及AddSearchPath添加的目录)，其次按照添加顺序检索文件系统
+func (s *Server) AddStaticFS(prefix string, fs http.FileSystem) {
+    if s.Status() == SERVER_STATUS_RUNNING {
+        glog.Error("cannot be changed while running")
+    }
+    prefix = "/" + strings.Trim(prefix, "/")
+    s.staticFS = append(s.staticFS, staticFSItem{prefix, fs})
+}
+
 // 获取日志写入的回调函数
 func (s *Server) GetLogHandler() func(r *Request, error ... interface{}) {
     if v := s.logHandler.Val(); v != nil {
@@ -390,6 +516,11 @@ func (s *Server)GetSessionMaxAge() int {
     return s.sessionMaxAge.Val()
 }
 
+// 获取http server参数 - SessionStorage
+func (s *Server)GetSessionStorage() SessionStorage {
+    return s.sessionStorage
+}
+
 // 获取http server参数 - SessionIdName
 func (s *Server)GetSessionIdName() string {
     return s.sessionIdName.Val()
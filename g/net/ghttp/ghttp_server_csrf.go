@@ -0,0 +1,139 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// CSRF防护.
+
+package ghttp
+
+import (
+    "errors"
+    "net/http"
+    "crypto/rand"
+    "crypto/subtle"
+    "encoding/hex"
+    "gitee.com/johng/gf/g/os/gview"
+    "gitee.com/johng/gf/g/encoding/ghtml"
+)
+
+const (
+    gCSRF_SESSION_KEY    = "__csrf_token" // Session中存放CSRF Token的键名
+    gDEFAULT_CSRF_HEADER = "X-CSRF-Token" // 默认的CSRF Token请求Header名称
+    gDEFAULT_CSRF_FIELD  = "_csrf"        // 默认的CSRF Token表单字段名称
+    gCSRF_TOKEN_BYTES    = 32             // CSRF Token随机字节数
+)
+
+// CSRF防护配置
+type CsrfConfig struct {
+    HeaderName string   // 提交CSRF Token的请求Header名称，默认为X-CSRF-Token
+    FieldName  string   // 提交CSRF Token的表单字段名称，默认为_csrf
+    Exempts    []string // 不需要CSRF校验的路由规则列表(例如供第三方调用的API)，pattern格式同BindHandler
+}
+
+// CSRF防护对象
+type serverCsrf struct {
+    header  string    // 请求Header名称
+    field   string    // 表单字段名称
+    exempts []*Router // 豁免的路由规则
+}
+
+// 开启CSRF防护，开启后所有路由服务方法的POST/PUT/DELETE/PATCH请求都必须通过请求Header或者表单字段
+// 提交与Session中一致的CSRF Token，否则返回403状态码；Token可在模板中通过内置函数csrf_token/csrf_field获取。
+func (s *Server) EnableCsrf(config...CsrfConfig) error {
+    if s.csrf != nil {
+        return errors.New("csrf protection is already enabled")
+    }
+    c := CsrfConfig{}
+    if len(config) > 0 {
+        c = config[0]
+    }
+    csrf := &serverCsrf {
+        header  : c.HeaderName,
+        field   : c.FieldName,
+        exempts : make([]*Router, 0, len(c.Exempts)),
+    }
+    if csrf.header == "" {
+        csrf.header = gDEFAULT_CSRF_HEADER
+    }
+    if csrf.field == "" {
+        csrf.field = gDEFAULT_CSRF_FIELD
+    }
+    for _, pattern := range c.Exempts {
+        router, err := s.newMatchRouter(pattern)
+        if err != nil {
+            return err
+        }
+        csrf.exempts = append(csrf.exempts, router)
+    }
+    if err := s.BindMiddlewareDefault(s.csrfMiddleware); err != nil {
+        return err
+    }
+    s.csrf = csrf
+    return nil
+}
+
+// CSRF校验中间件
+func (s *Server) csrfMiddleware(r *Request) {
+    if !s.csrf.check(r) {
+        r.Response.WriteStatus(http.StatusForbidden, "Invalid CSRF Token")
+        return
+    }
+    r.Middleware.Next()
+}
+
+// 校验请求的CSRF Token，安全的请求方法及豁免的路由不做校验
+func (c *serverCsrf) check(r *Request) bool {
+    switch r.Method {
+        case "POST", "PUT", "DELETE", "PATCH":
+        default:
+            return true
+    }
+    for _, router := range c.exempts {
+        if router.match(r.Method, r.URL.Path, r.GetHost()) != nil {
+            return true
+        }
+    }
+    expect := r.Session.GetString(gCSRF_SESSION_KEY)
+    if expect == "" {
+        return false
+    }
+    token := r.Header.Get(c.header)
+    if token == "" {
+        token = r.GetPostString(c.field)
+    }
+    return subtle.ConstantTimeCompare([]byte(token), []byte(expect)) == 1
+}
+
+// 获取当前Session的CSRF Token，不存在时自动生成并保存到Session中
+func (r *Request) GetCsrfToken() string {
+    token := r.Session.GetString(gCSRF_SESSION_KEY)
+    if token == "" {
+        b := make([]byte, gCSRF_TOKEN_BYTES)
+        if _, err := rand.Read(b); err != nil {
+            panic(err)
+        }
+        token = hex.EncodeToString(b)
+        r.Session.Set(gCSRF_SESSION_KEY, token)
+    }
+    return token
+}
+
+// 获取CSRF Token表单字段名称
+func (s *Server) getCsrfFieldName() string {
+    if s.csrf != nil {
+        return s.csrf.field
+    }
+    return gDEFAULT_CSRF_FIELD
+}
+
+// 模板内置函数: csrf_token，返回CSRF Token
+func (r *Response) funcCsrfToken() string {
+    return r.request.GetCsrfToken()
+}
+
+// 模板内置函数: csrf_field，返回包含CSRF Token的隐藏表单字段
+func (r *Response) funcCsrfField() gview.HTML {
+    return gview.HTML(`<input type="hidden" name="` + ghtml.SpecialChars(r.Server.getCsrfFieldName()) +
+        `" value="` + r.request.GetCsrfToken() + `">`)
+}
@@ -39,9 +39,9 @@ func (s *Server) Domain(domains string) *Domain {
 }
 
 // 注意该方法是直接绑定方法的内存地址，执行的时候直接执行该方法，不会存在初始化新的控制器逻辑
-func (d *Domain) BindHandler(pattern string, handler HandlerFunc) error {
+func (d *Domain) BindHandler(pattern string, handler HandlerFunc, name...string) error {
     for domain, _ := range d.m {
-        if err := d.s.BindHandler(pattern + "@" + domain, handler); err != nil {
+        if err := d.s.BindHandler(pattern + "@" + domain, handler, name...); err != nil {
             return err
         }
     }
@@ -61,10 +61,20 @@ func (d *Domain) BindObject(pattern string, obj interface{}, methods...string) e
     return nil
 }
 
+// 执行对象注册，并为注册的每个方法路由命名，路由名称格式为：name.方法名称
+func (d *Domain) BindObjectWithName(pattern string, obj interface{}, name string, methods...string) error {
+    for domain, _ := range d.m {
+        if err := d.s.BindObjectWithName(pattern + "@" + domain, obj, name, methods...); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
 // 执行对象方法注册，methods参数不区分大小写
-func (d *Domain) BindObjectMethod(pattern string, obj interface{}, method string) error {
+func (d *Domain) BindObjectMethod(pattern string, obj interface{}, method string, name...string) error {
     for domain, _ := range d.m {
-        if err := d.s.BindObjectMethod(pattern + "@" + domain, obj, method); err != nil {
+        if err := d.s.BindObjectMethod(pattern + "@" + domain, obj, method, name...); err != nil {
             return err
         }
     }
@@ -72,9 +82,9 @@ func (d *Domain) BindObjectMethod(pattern string, obj interface{}, method string
 }
 
 // RESTful执行对象注册
-func (d *Domain) BindObjectRest(pattern string, obj interface{}) error {
+func (d *Domain) BindObjectRest(pattern string, obj interface{}, name...string) error {
     for domain, _ := range d.m {
-        if err := d.s.BindObjectRest(pattern + "@" + domain, obj); err != nil {
+        if err := d.s.BindObjectRest(pattern + "@" + domain, obj, name...); err != nil {
             return err
         }
     }
@@ -94,10 +104,20 @@ func (d *Domain) BindController(pattern string, c Controller, methods...string)
     return nil
 }
 
+// 控制器注册，并为注册的每个方法路由命名，路由名称格式为：name.方法名称
+func (d *Domain) BindControllerWithName(pattern string, c Controller, name string, methods...string) error {
+    for domain, _ := range d.m {
+        if err := d.s.BindControllerWithName(pattern + "@" + domain, c, name, methods...); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
 // 控制器方法注册，methods参数区分大小写
-func (d *Domain) BindControllerMethod(pattern string, c Controller, method string) error {
+func (d *Domain) BindControllerMethod(pattern string, c Controller, method string, name...string) error {
     for domain, _ := range d.m {
-        if err := d.s.BindControllerMethod(pattern + "@" + domain, c, method); err != nil {
+        if err := d.s.BindControllerMethod(pattern + "@" + domain, c, method, name...); err != nil {
             return err
         }
     }
@@ -105,9 +125,9 @@ func (d *Domain) BindControllerMethod(pattern string, c Controller, method strin
 }
 
 // RESTful控制器注册
-func (d *Domain) BindControllerRest(pattern string, c Controller) error {
+func (d *Domain) BindControllerRest(pattern string, c Controller, name...string) error {
     for domain, _ := range d.m {
-        if err := d.s.BindControllerRest(pattern + "@" + domain, c); err != nil {
+        if err := d.s.BindControllerRest(pattern + "@" + domain, c, name...); err != nil {
             return err
         }
     }
@@ -44,7 +44,7 @@ func (s *Server) newGracefulServer(addr string, fd...int) *gracefulServer {
 
 // 生成一个底层的Web Server对象
 func (s *Server) newHttpServer(addr string) *http.Server {
-    return &http.Server {
+    server := &http.Server {
         Addr           : addr,
         Handler        : s.config.Handler,
         ReadTimeout    : s.config.ReadTimeout,
@@ -52,6 +52,14 @@ func (s *Server) newHttpServer(addr string) *http.Server {
         IdleTimeout    : s.config.IdleTimeout,
         MaxHeaderBytes : s.config.MaxHeaderBytes,
     }
+    // HTTPS服务的HTTP/2由标准库通过ALPN协商(TLSNextProto为nil时自动支持)，
+    // 没有开启时设置为空的TLSNextProto，只使用HTTP/1.1
+    if !s.config.HTTP2Enabled {
+        server.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+    }
+    // HTTP服务不加密的HTTP/2(h2c)
+    s.setH2C(server)
+    return server
 }
 
 // 执行HTTP监听
@@ -82,27 +90,13 @@ func (s *gracefulServer) setFd(fd int) {
     s.fd = uintptr(fd)
 }
 
-// 执行HTTPS监听
-func (s *gracefulServer) ListenAndServeTLS(certFile, keyFile string) error {
-    addr   := s.httpServer.Addr
-    config := &tls.Config{}
-    if s.httpServer.TLSConfig != nil {
-        *config = *s.httpServer.TLSConfig
-    }
-    if config.NextProtos == nil {
-        config.NextProtos = []string{"http/1.1"}
-    }
-    var err error
-    config.Certificates         = make([]tls.Certificate, 1)
-    config.Certificates[0], err = tls.LoadX509KeyPair(certFile, keyFile)
-    if err != nil {
-        return err
-    }
+// 执行HTTPS监听，证书由config提供(支持SNI及证书热更新)
+func (s *gracefulServer) ListenAndServeTLS(config *tls.Config) error {
+    addr    := s.httpServer.Addr
     ln, err := s.getNetListener(addr)
     if err != nil {
         return err
     }
-
     s.listener    = tls.NewListener(ln, config)
     s.rawListener = ln
     return s.doServe()
@@ -0,0 +1,171 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 分组路由管理.
+
+package ghttp
+
+import (
+    "strings"
+)
+
+// 分组路由对象
+type RouterGroup struct {
+    server *Server // 所属Server
+    domain *Domain // 所属Domain(为nil时表示直接注册到Server)
+    prefix string  // 分组路由前缀(包含所有父级分组的前缀)
+}
+
+// 创建分组路由对象，该分组下注册的所有路由规则都会自动加上prefix前缀
+func (s *Server) Group(prefix string) *RouterGroup {
+    return &RouterGroup{
+        server : s,
+        prefix : formatGroupPrefix(prefix),
+    }
+}
+
+// 创建域名下的分组路由对象
+func (d *Domain) Group(prefix string) *RouterGroup {
+    return &RouterGroup{
+        server : d.s,
+        domain : d,
+        prefix : formatGroupPrefix(prefix),
+    }
+}
+
+// 在当前分组下创建子分组，子分组的前缀会拼接在父级分组前缀之后
+func (g *RouterGroup) Group(prefix string) *RouterGroup {
+    return &RouterGroup{
+        server : g.server,
+        domain : g.domain,
+        prefix : g.prefix + formatGroupPrefix(prefix),
+    }
+}
+
+// 获取分组路由前缀
+func (g *RouterGroup) Prefix() string {
+    return g.prefix
+}
+
+// 注意该方法是直接绑定函数的内存地址，执行的时候直接执行该方法，不会存在初始化新的控制器逻辑
+func (g *RouterGroup) BindHandler(pattern string, handler HandlerFunc, name...string) error {
+    if g.domain != nil {
+        return g.domain.BindHandler(g.getPrefixedPattern(pattern), handler, name...)
+    }
+    return g.server.BindHandler(g.getPrefixedPattern(pattern), handler, name...)
+}
+
+// 执行对象注册
+func (g *RouterGroup) BindObject(pattern string, obj interface{}, methods...string) error {
+    if g.domain != nil {
+        return g.domain.BindObject(g.getPrefixedPattern(pattern), obj, methods...)
+    }
+    return g.server.BindObject(g.getPrefixedPattern(pattern), obj, methods...)
+}
+
+// 执行对象注册，并为注册的每个方法路由命名，路由名称格式为：name.方法名称
+func (g *RouterGroup) BindObjectWithName(pattern string, obj interface{}, name string, methods...string) error {
+    if g.domain != nil {
+        return g.domain.BindObjectWithName(g.getPrefixedPattern(pattern), obj, name, methods...)
+    }
+    return g.server.BindObjectWithName(g.getPrefixedPattern(pattern), obj, name, methods...)
+}
+
+// 执行对象方法注册
+func (g *RouterGroup) BindObjectMethod(pattern string, obj interface{}, method string, name...string) error {
+    if g.domain != nil {
+        return g.domain.BindObjectMethod(g.getPrefixedPattern(pattern), obj, method, name...)
+    }
+    return g.server.BindObjectMethod(g.getPrefixedPattern(pattern), obj, method, name...)
+}
+
+// RESTful执行对象注册
+func (g *RouterGroup) BindObjectRest(pattern string, obj interface{}, name...string) error {
+    if g.domain != nil {
+        return g.domain.BindObjectRest(g.getPrefixedPattern(pattern), obj, name...)
+    }
+    return g.server.BindObjectRest(g.getPrefixedPattern(pattern), obj, name...)
+}
+
+// 控制器注册
+func (g *RouterGroup) BindController(pattern string, c Controller, methods...string) error {
+    if g.domain != nil {
+        return g.domain.BindController(g.getPrefixedPattern(pattern), c, methods...)
+    }
+    return g.server.BindController(g.getPrefixedPattern(pattern), c, methods...)
+}
+
+// 控制器注册，并为注册的每个方法路由命名，路由名称格式为：name.方法名称
+func (g *RouterGroup) BindControllerWithName(pattern string, c Controller, name string, methods...string) error {
+    if g.domain != nil {
+        return g.domain.BindControllerWithName(g.getPrefixedPattern(pattern), c, name, methods...)
+    }
+    return g.server.BindControllerWithName(g.getPrefixedPattern(pattern), c, name, methods...)
+}
+
+// 控制器方法注册
+func (g *RouterGroup) BindControllerMethod(pattern string, c Controller, method string, name...string) error {
+    if g.domain != nil {
+        return g.domain.BindControllerMethod(g.getPrefixedPattern(pattern), c, method, name...)
+    }
+    return g.server.BindControllerMethod(g.getPrefixedPattern(pattern), c, method, name...)
+}
+
+// RESTful控制器注册
+func (g *RouterGroup) BindControllerRest(pattern string, c Controller, name...string) error {
+    if g.domain != nil {
+        return g.domain.BindControllerRest(g.getPrefixedPattern(pattern), c, name...)
+    }
+    return g.server.BindControllerRest(g.getPrefixedPattern(pattern), c, name...)
+}
+
+// 绑定指定的hook回调函数，pattern同样会自动加上分组前缀。
+// 如果需要对整个分组生效，pattern可以使用"/*"。
+func (g *RouterGroup) BindHookHandler(pattern string, hook string, handler HandlerFunc) error {
+    if g.domain != nil {
+        return g.domain.BindHookHandler(g.getPrefixedPattern(pattern), hook, handler)
+    }
+    return g.server.BindHookHandler(g.getPrefixedPattern(pattern), hook, handler)
+}
+
+// 通过map批量绑定回调函数
+func (g *RouterGroup) BindHookHandlerByMap(pattern string, hookmap map[string]HandlerFunc) error {
+    if g.domain != nil {
+        return g.domain.BindHookHandlerByMap(g.getPrefixedPattern(pattern), hookmap)
+    }
+    return g.server.BindHookHandlerByMap(g.getPrefixedPattern(pattern), hookmap)
+}
+
+// 将分组前缀合并到pattern的URI中，pattern中的HTTP Method及域名会被保留
+func (g *RouterGroup) getPrefixedPattern(pattern string) string {
+    domain, method, uri, err := g.server.parsePattern(pattern)
+    if err != nil || uri == "/" {
+        uri = ""
+    }
+    // 相对路径的pattern(如"user")需要补全"/"，否则会与前缀直接拼接为"/apiuser"
+    if uri != "" && uri[0] != '/' {
+        uri = "/" + uri
+    }
+    uri = g.prefix + uri
+    if uri == "" {
+        uri = "/"
+    }
+    if !strings.EqualFold(method, gDEFAULT_METHOD) {
+        uri = method + ":" + uri
+    }
+    if !strings.EqualFold(domain, gDEFAULT_DOMAIN) {
+        uri = uri + "@" + domain
+    }
+    return uri
+}
+
+// 格式化分组前缀，保证以"/"开头并且末尾没有"/"，根前缀格式化为空字符串
+func formatGroupPrefix(prefix string) string {
+    prefix = strings.Trim(strings.TrimSpace(prefix), "/")
+    if prefix == "" {
+        return ""
+    }
+    return "/" + prefix
+}
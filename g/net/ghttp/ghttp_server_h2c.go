@@ -0,0 +1,23 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+// +build go1.24
+
+package ghttp
+
+import "net/http"
+
+// 开启HTTP服务不加密的HTTP/2(h2c)，需要客户端直接使用HTTP/2协议
+func (s *Server) setH2C(server *http.Server) {
+    if !s.config.H2CEnabled {
+        return
+    }
+    protocols := new(http.Protocols)
+    protocols.SetHTTP1(true)
+    protocols.SetHTTP2(s.config.HTTP2Enabled)
+    protocols.SetUnencryptedHTTP2(true)
+    server.Protocols = protocols
+}
@@ -0,0 +1,21 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+// +build !go1.24
+
+package ghttp
+
+import (
+    "net/http"
+    "gitee.com/johng/gf/g/os/glog"
+)
+
+// Go 1.24以下版本的标准库不支持h2c，开启时只输出错误日志
+func (s *Server) setH2C(server *http.Server) {
+    if s.config.H2CEnabled {
+        glog.Error("h2c is not supported by current go version, go1.24 or later required")
+    }
+}
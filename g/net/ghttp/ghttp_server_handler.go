@@ -8,7 +8,6 @@
 package ghttp
 
 import (
-    "os"
     "fmt"
     "sort"
     "reflect"
@@ -37,6 +36,7 @@ func (s *Server)handleRequest(w http.ResponseWriter, r *http.Request) {
 
     // 创建请求处理对象
     request := newRequest(s, r, w)
+    s.handleMetricsEnter()
 
     defer func() {
         if request.LeaveTime == 0 {
@@ -48,28 +48,47 @@ func (s *Server)handleRequest(w http.ResponseWriter, r *http.Request) {
         if e := recover(); e != nil {
             s.handleErrorLog(e, request)
         }
+        // 处理过程中产生panic时Session数据仍然需要写回存储(正常流程中已写回时不会重复执行)
+        request.Session.close()
+        // 监控统计(需要在错误处理之后，以便记录正确的状态码)
+        s.handleMetricsLeave(request)
         // 将Request对象指针丢到队列中异步关闭
         s.closeQueue.PushBack(request)
     }()
 
-    // 优先执行静态文件检索
-    filePath := s.paths.Search(r.URL.Path)
-    if filePath != "" {
-        if gfile.IsDir(filePath) {
-            // 如果是目录需要处理index files
-            if len(s.config.IndexFiles) > 0 {
-                for _, file := range s.config.IndexFiles {
-                    fpath := s.paths.Search(filePath + gfile.Separator + file)
-                    if fpath != "" {
-                        filePath              = fpath
-                        request.isFileRequest = true
-                        break
-                    }
-                }
-            }
-        } else {
-            request.isFileRequest = true
+    // IP访问控制
+    if !s.isIpAllowed(request) {
+        request.Response.WriteStatus(http.StatusForbidden)
+        request.Response.OutputBuffer()
+        return
+    }
+
+    // 全局请求频率及并发数限制，超过限制时直接输出，不再执行后续流程
+    if s.limiter != nil {
+        release, ok := s.limiter.check(request)
+        if !ok {
+            request.Response.OutputBuffer()
+            return
+        }
+        if release != nil {
+            defer release()
+        }
+    }
+
+    // 请求体大小限制
+    if max := s.config.ClientMaxBodySize; max > 0 {
+        if request.ContentLength > max {
+            request.Response.WriteStatus(http.StatusRequestEntityTooLarge)
+            request.Response.OutputBuffer()
+            return
         }
+        request.Body = http.MaxBytesReader(w, request.Body, max)
+    }
+
+    // 优先执行静态文件检索
+    file := s.searchStaticFile(r.URL.Path)
+    if file != nil && !file.dir {
+        request.isFileRequest = true
     }
 
     // 其次进行服务路由信息检索
@@ -82,6 +101,12 @@ func (s *Server)handleRequest(w http.ResponseWriter, r *http.Request) {
             }
             request.Router = parsedItem.handler.router
         }
+        // 本地代理，改写为目标路由后按照正常流程执行目标路由的事件回调、中间件及服务方法
+        if handler != nil && handler.rtype == gROUTE_REGISTER_REWRITE {
+            if handler = s.resolveLocalProxy(request, handler); handler == nil {
+                request.Exit()
+            }
+        }
     }
 
     // 事件 - BeforeServe
@@ -89,11 +114,14 @@ func (s *Server)handleRequest(w http.ResponseWriter, r *http.Request) {
 
     // 执行静态文件服务/回调控制器/执行对象/方法
     if !request.exit.Val() {
-        if filePath != "" && (request.IsFileRequest() || handler == nil) {
-            s.serveFile(request, filePath)
+        if file != nil && (request.IsFileRequest() || handler == nil) {
+            s.serveStaticFile(request, file)
         } else {
             if handler != nil {
-                s.callServeHandler(handler, request)
+                // 按照注册顺序执行匹配的中间件，最终由中间件调用链执行服务方法
+                request.Middleware.items   = s.getMiddlewareHandlerWithCache(request)
+                request.Middleware.handler = handler
+                request.Middleware.Next()
             } else {
                 request.Response.WriteStatus(http.StatusNotFound)
             }
@@ -108,6 +136,8 @@ func (s *Server)handleRequest(w http.ResponseWriter, r *http.Request) {
 
     // 事件 - BeforeOutput
     s.callHookHandler(HOOK_BEFORE_OUTPUT, request)
+    // Session数据写回存储(需要在输出之前执行，保证客户端的下一次请求能够读取到最新的Session数据)
+    request.Session.close()
     // 输出Cookie
     request.Cookie.Output()
     // 输出缓冲区
@@ -150,24 +180,10 @@ func (s *Server)serveFile(r *Request, path string) {
         r.Response.WriteStatus(http.StatusNotFound)
         return
     }
-    f, err := os.Open(path)
-    if err != nil {
-        r.Response.WriteStatus(http.StatusForbidden)
-        return
-    }
-    defer f.Close()
-    info, _ := f.Stat()
-    if info.IsDir() {
-        if s.config.IndexFolder {
-            s.listDir(r, f)
-        } else {
-            r.Response.WriteStatus(http.StatusForbidden)
-        }
-    } else {
-        // 读取文件内容返回, no buffer
-        r.Response.length = int(info.Size())
-        http.ServeContent(r.Response.Writer, &r.Request, info.Name(), info.ModTime(), f)
-    }
+    s.serveStaticFile(r, &staticFile {
+        fs   : osFileSystem{},
+        path : path,
+    })
 }
 
 // 目录列表
@@ -199,9 +215,11 @@ func (s *Server) startCloseQueueLoop() {
             if v := s.closeQueue.PopFront(); v != nil {
                 r := v.(*Request)
                 s.callHookHandler(HOOK_BEFORE_CLOSE, r)
-                // 更新Session会话超时时间
-                r.Session.UpdateExpire()
                 s.callHookHandler(HOOK_AFTER_CLOSE, r)
+                // 清理表单解析时产生的上传临时文件
+                if r.MultipartForm != nil {
+                    r.MultipartForm.RemoveAll()
+                }
             }
         }
     }()
@@ -0,0 +1,242 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// IP访问控制及可信代理.
+
+package ghttp
+
+import (
+    "net"
+    "errors"
+    "strings"
+    "strconv"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/os/gcfg"
+    "gitee.com/johng/gf/g/os/gfsnotify"
+    "gitee.com/johng/gf/g/container/gtype"
+)
+
+// 单条IP规则
+type ipRule struct {
+    network  *net.IPNet // CIDR网段(精确IP转换为全掩码网段)
+    segments []string   // 通配符规则的分段(IPv4为4段十进制，IPv6为8段十六进制)
+    ipv6     bool       // 通配符规则是否为IPv6
+}
+
+// IP规则列表
+type ipRules []*ipRule
+
+// 解析IP规则列表，支持以下格式：
+// 1. 精确IP，如：192.168.1.1, ::1；
+// 2. CIDR网段，如：10.0.0.0/8, 2001:db8::/32；
+// 3. 通配符，如：192.168.*.*, 192.168.1.*, 2001:db8:*:*:*:*:*:*；
+// 4. 前缀(兼容旧版本)，如：10, 192.168，等同于10.*.*.*, 192.168.*.*。
+func parseIpRules(rules []string) (ipRules, error) {
+    result := make(ipRules, 0, len(rules))
+    for _, v := range rules {
+        v = strings.TrimSpace(v)
+        if v == "" {
+            continue
+        }
+        rule, err := parseIpRule(v)
+        if err != nil {
+            return nil, err
+        }
+        result = append(result, rule)
+    }
+    return result, nil
+}
+
+// 解析单条IP规则
+func parseIpRule(rule string) (*ipRule, error) {
+    if strings.Contains(rule, "/") {
+        _, network, err := net.ParseCIDR(rule)
+        if err != nil {
+            return nil, errors.New(`invalid ip rule "` + rule + `": ` + err.Error())
+        }
+        return &ipRule{ network : network }, nil
+    }
+    if ip := net.ParseIP(rule); ip != nil {
+        bits := 128
+        if ip.To4() != nil {
+            ip, bits = ip.To4(), 32
+        }
+        return &ipRule{ network : &net.IPNet{ IP : ip, Mask : net.CIDRMask(bits, bits) } }, nil
+    }
+    // 通配符及前缀规则
+    r := &ipRule{ ipv6 : strings.Contains(rule, ":") }
+    sep, count, base, max := ".", 4, 10, 255
+    if r.ipv6 {
+        if strings.Contains(rule, "::") {
+            return nil, errors.New(`invalid ip rule "` + rule + `": wildcard rule cannot contain "::"`)
+        }
+        sep, count, base, max = ":", 8, 16, 0xffff
+    }
+    r.segments = strings.Split(strings.TrimRight(rule, sep), sep)
+    if len(r.segments) > count {
+        return nil, errors.New(`invalid ip rule "` + rule + `"`)
+    }
+    for _, s := range r.segments {
+        if s == "*" {
+            continue
+        }
+        if n, err := strconv.ParseUint(s, base, 32); err != nil || int(n) > max {
+            return nil, errors.New(`invalid ip rule "` + rule + `"`)
+        }
+    }
+    for len(r.segments) < count {
+        r.segments = append(r.segments, "*")
+    }
+    return r, nil
+}
+
+// 判断IP是否匹配规则
+func (r *ipRule) match(ip net.IP) bool {
+    if r.network != nil {
+        return r.network.Contains(ip)
+    }
+    if ip4 := ip.To4(); ip4 != nil {
+        if r.ipv6 {
+            return false
+        }
+        for i, s := range r.segments {
+            if s != "*" && s != strconv.Itoa(int(ip4[i])) {
+                return false
+            }
+        }
+        return true
+    }
+    if !r.ipv6 {
+        return false
+    }
+    for i, s := range r.segments {
+        if s == "*" {
+            continue
+        }
+        n, _ := strconv.ParseUint(s, 16, 32)
+        if int(n) != int(ip[i*2]) << 8 | int(ip[i*2 + 1]) {
+            return false
+        }
+    }
+    return true
+}
+
+// 判断IP是否匹配规则列表中的任意一条规则
+func (rs ipRules) match(ip string) bool {
+    if len(rs) == 0 {
+        return false
+    }
+    parsed := net.ParseIP(ip)
+    if parsed == nil {
+        return false
+    }
+    for _, r := range rs {
+        if r.match(parsed) {
+            return true
+        }
+    }
+    return false
+}
+
+// 解析IP规则列表，无效的规则会输出错误日志并忽略
+func mustParseIpRules(rules []string) ipRules {
+    result := make(ipRules, 0, len(rules))
+    for _, v := range rules {
+        if rs, err := parseIpRules([]string{v}); err != nil {
+            glog.Error(err)
+        } else {
+            result = append(result, rs...)
+        }
+    }
+    return result
+}
+
+// 获取已解析的IP规则列表
+func getIpRules(v *gtype.Interface) ipRules {
+    if r := v.Val(); r != nil {
+        return r.(ipRules)
+    }
+    return nil
+}
+
+// 从配置管理对象中加载IP访问控制规则，并在配置文件变更时自动重新加载(可在Server运行时使用)。
+// 配置项名称同ServerConfig：DenyIps、AllowIps、TrustedProxies，section为配置项所在的层级，如："server"，
+// 为空时表示配置项在配置文件根层级；file为可选的配置文件名称，不传递时使用配置管理对象的默认配置文件。
+func (s *Server) SetIpRulesFromConfig(config *gcfg.Config, section string, file...string) error {
+    path := config.GetFilePath(file...)
+    if path == "" {
+        return errors.New("config file not found")
+    }
+    prefix := ""
+    if section != "" {
+        prefix = section + "."
+    }
+    load := func() {
+        s.SetDenyIps(config.GetStrings(prefix + "DenyIps", file...))
+        s.SetAllowIps(config.GetStrings(prefix + "AllowIps", file...))
+        s.SetTrustedProxies(config.GetStrings(prefix + "TrustedProxies", file...))
+    }
+    load()
+    return gfsnotify.Add(path, func(event *gfsnotify.Event) {
+        // 清除配置缓存，保证读取到的是最新的配置文件内容
+        config.Reload()
+        load()
+        glog.Debugfln(`ip rules reloaded from "%s"`, path)
+    })
+}
+
+// 判断请求客户端IP是否允许访问，先判断禁止列表，再判断允许列表。
+// 访问控制使用的客户端IP只会从可信代理转发的Header中获取，防止伪造Header绕过限制。
+func (s *Server) isIpAllowed(r *Request) bool {
+    deny  := getIpRules(s.denyIps)
+    allow := getIpRules(s.allowIps)
+    if len(deny) == 0 && len(allow) == 0 {
+        return true
+    }
+    ip := r.getTrustedClientIp()
+    if deny.match(ip) {
+        return false
+    }
+    if len(allow) > 0 && !allow.match(ip) {
+        return false
+    }
+    return true
+}
+
+// 获取请求的直连IP地址(TCP连接的对端地址)
+func (r *Request) getRemoteIp() string {
+    if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+        return host
+    }
+    return r.RemoteAddr
+}
+
+// 获取可信的客户端IP地址：只有当直连地址为可信代理时才会使用X-Forwarded-For/X-Real-IP，
+// X-Forwarded-For从右往左查找，第一个不是可信代理的地址即为客户端地址。
+func (r *Request) getTrustedClientIp() string {
+    ip      := r.getRemoteIp()
+    trusted := getIpRules(r.Server.trustedProxies)
+    if !trusted.match(ip) {
+        return ip
+    }
+    if forwarded := strings.Join(r.Header["X-Forwarded-For"], ","); forwarded != "" {
+        array := strings.Split(forwarded, ",")
+        for i := len(array) - 1; i >= 0; i-- {
+            v := strings.TrimSpace(array[i])
+            if net.ParseIP(v) == nil {
+                break
+            }
+            ip = v
+            if !trusted.match(v) {
+                break
+            }
+        }
+        return ip
+    }
+    if v := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(v) != nil {
+        return v
+    }
+    return ip
+}
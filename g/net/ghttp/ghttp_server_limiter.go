@@ -0,0 +1,316 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 请求频率限制(令牌桶)及并发数限制.
+
+package ghttp
+
+import (
+    "sync"
+    "math"
+    "time"
+    "errors"
+    "strconv"
+    "net/http"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/os/gtime"
+    "gitee.com/johng/gf/g/util/gconv"
+    "gitee.com/johng/gf/g/database/gredis"
+)
+
+const (
+    LIMITER_KEY_IP            = 0             // 按照客户端IP进行限制
+    LIMITER_KEY_SESSION       = 1             // 按照SessionId进行限制(请求没有有效的SessionId时按照IP限制)
+    gLIMITER_REDIS_KEY_PREFIX = "gf_limiter_" // Redis中计数器键名的默认前缀
+    gLIMITER_CLEAR_INTERVAL   = time.Minute   // 内存令牌桶的清理时间间隔(所有限制对象共享一个清理goroutine)
+)
+
+// Redis令牌桶脚本，KEYS[1]为计数器键名，ARGV依次为：每秒令牌数、桶容量、当前时间(毫秒)，
+// 返回0表示允许访问，否则返回需要等待的毫秒数
+const gLIMITER_REDIS_SCRIPT = `
+local rate  = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now   = tonumber(ARGV[3])
+local v     = redis.call('HMGET', KEYS[1], 'tokens', 'time')
+local tokens, last = tonumber(v[1]), tonumber(v[2])
+if tokens == nil or last == nil then
+    tokens, last = burst, now
+end
+tokens = math.min(burst, tokens + math.max(0, now - last) * rate / 1000)
+local wait = 0
+if tokens >= 1 then
+    tokens = tokens - 1
+else
+    wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'time', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return wait
+`
+
+// 请求限制配置
+type LimiterConfig struct {
+    Rate        float64                 // 每秒允许的请求数(令牌生成速率)，为0时表示不限制请求频率
+    Burst       int                     // 允许的突发请求数(令牌桶容量)，默认为Rate向上取整
+    KeyBy       int                     // 限制的维度：LIMITER_KEY_IP/LIMITER_KEY_SESSION，默认按照IP，SessionId不存在于存储中时按照IP
+    KeyFunc     func(r *Request) string // 自定义限制维度，优先级高于KeyBy，返回空字符串时表示不限制该请求
+    Concurrency int                     // 同一维度下允许同时处理的最大请求数，为0时表示不限制(只在当前服务实例内有效)
+    Redis       *gredis.Config          // 使用Redis存储频率计数器，以便多个服务实例共享限制，为nil时使用内存存储
+    RedisPrefix string                  // Redis中计数器键名的前缀
+}
+
+// 内存令牌桶
+type limiterBucket struct {
+    tokens  float64 // 当前令牌数
+    last    int64   // 上一次更新令牌数的时间(纳秒)
+    running int     // 当前正在处理的请求数
+}
+
+// 需要定时清理内存令牌桶的限制对象，所有限制对象共享一个清理goroutine，Server关闭时移除其创建的限制对象
+var (
+    limitersMu    sync.Mutex
+    limiters      = make(map[*serverLimiter]struct{})
+    limitersClear sync.Once
+)
+
+// 请求限制对象
+type serverLimiter struct {
+    mu      sync.Mutex                // 令牌桶互斥锁
+    name    string                    // 限制器名称(绑定的路由规则)，用于区分Redis中不同限制器的计数器
+    config  LimiterConfig             // 限制配置
+    buckets map[string]*limiterBucket // 内存令牌桶
+}
+
+// 设置全局请求限制，对所有请求(包括静态文件)生效，超过限制时返回429状态码
+func (s *Server) SetLimiter(config LimiterConfig) error {
+    if s.Status() == SERVER_STATUS_RUNNING {
+        glog.Error("cannot be changed while running")
+        return errors.New("cannot be changed while running")
+    }
+    l, err := s.newServerLimiter("*", config)
+    if err != nil {
+        return err
+    }
+    s.config.Limiter = &config
+    s.limiter        = l
+    return nil
+}
+
+// 绑定请求限制到指定的路由规则，pattern格式同BindHandler，超过限制时返回429状态码，
+// 可通过BindStatusHandler(429, ...)自定义返回内容
+func (s *Server) BindLimiter(pattern string, config LimiterConfig) error {
+    l, err := s.newServerLimiter(pattern, config)
+    if err != nil {
+        return err
+    }
+    return s.BindMiddleware(pattern, l.middleware)
+}
+
+// 绑定请求限制到域名下的路由规则
+func (d *Domain) BindLimiter(pattern string, config LimiterConfig) error {
+    for domain, _ := range d.m {
+        if err := d.s.BindLimiter(pattern + "@" + domain, config); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// 绑定请求限制到分组下的路由规则
+func (g *RouterGroup) BindLimiter(pattern string, config LimiterConfig) error {
+    if g.domain != nil {
+        return g.domain.BindLimiter(g.getPrefixedPattern(pattern), config)
+    }
+    return g.server.BindLimiter(g.getPrefixedPattern(pattern), config)
+}
+
+// 创建请求限制对象，并注册到定时清理中
+func (s *Server) newServerLimiter(name string, config LimiterConfig) (*serverLimiter, error) {
+    if config.Rate < 0 || config.Burst < 0 || config.Concurrency < 0 {
+        return nil, errors.New("invalid limiter config: negative value")
+    }
+    if config.Rate == 0 && config.Concurrency == 0 {
+        return nil, errors.New("invalid limiter config: Rate and Concurrency cannot both be 0")
+    }
+    if config.Rate > 0 && config.Burst == 0 {
+        config.Burst = int(math.Ceil(config.Rate))
+    }
+    if config.Redis != nil && config.RedisPrefix == "" {
+        config.RedisPrefix = gLIMITER_REDIS_KEY_PREFIX
+    }
+    l := &serverLimiter {
+        name    : name,
+        config  : config,
+        buckets : make(map[string]*limiterBucket),
+    }
+    s.limitersMu.Lock()
+    s.limiters = append(s.limiters, l)
+    s.limitersMu.Unlock()
+    limitersMu.Lock()
+    limiters[l] = struct{}{}
+    limitersMu.Unlock()
+    // 定时清理已经装满并且没有正在处理请求的令牌桶，整个进程只有一个清理goroutine
+    limitersClear.Do(func() {
+        gtime.SetInterval(gLIMITER_CLEAR_INTERVAL, func() bool {
+            limitersMu.Lock()
+            list := make([]*serverLimiter, 0, len(limiters))
+            for l, _ := range limiters {
+                list = append(list, l)
+            }
+            limitersMu.Unlock()
+            for _, l := range list {
+                l.clearIdle()
+            }
+            return true
+        })
+    })
+    return l, nil
+}
+
+// 移除Server创建的所有限制对象的定时清理(Server关闭时调用)
+func (s *Server) closeLimiters() {
+    s.limitersMu.Lock()
+    list := s.limiters
+    s.limiters = nil
+    s.limitersMu.Unlock()
+    limitersMu.Lock()
+    for _, l := range list {
+        delete(limiters, l)
+    }
+    limitersMu.Unlock()
+}
+
+// 中间件方式执行请求限制
+func (l *serverLimiter) middleware(r *Request) {
+    release, ok := l.check(r)
+    if !ok {
+        return
+    }
+    if release != nil {
+        defer release()
+    }
+    r.Middleware.Next()
+}
+
+// 执行请求限制检查，超过限制时输出429状态码并返回false；
+// 如果开启了并发数限制，返回的release方法需要在请求处理结束后调用
+func (l *serverLimiter) check(r *Request) (release func(), ok bool) {
+    key := l.key(r)
+    if key == "" {
+        return nil, true
+    }
+    if l.config.Rate > 0 {
+        if wait := l.wait(key); wait > 0 {
+            r.Response.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+            r.Response.WriteStatus(http.StatusTooManyRequests)
+            return nil, false
+        }
+    }
+    if l.config.Concurrency > 0 {
+        if !l.acquire(key) {
+            r.Response.WriteStatus(http.StatusTooManyRequests)
+            return nil, false
+        }
+        return func() { l.release(key) }, true
+    }
+    return nil, true
+}
+
+// 获取请求的限制维度键名
+func (l *serverLimiter) key(r *Request) string {
+    if l.config.KeyFunc != nil {
+        return l.config.KeyFunc(r)
+    }
+    // SessionId由客户端提交，只有存储中存在的SessionId才作为限制维度，防止每次请求使用随机的SessionId绕过限制
+    if l.config.KeyBy == LIMITER_KEY_SESSION {
+        if id := r.Cookie.Get(r.Server.GetSessionIdName()); id != "" {
+            if data, err := r.Server.sessionStorage.Get(id); err == nil && data != nil {
+                return "session:" + id
+            }
+        }
+    }
+    // 只使用可信的客户端IP，防止通过伪造X-Real-IP/X-Forwarded-For绕过限制
+    return "ip:" + r.getTrustedClientIp()
+}
+
+// 获取一个令牌，返回0表示获取成功，否则返回需要等待的时间
+func (l *serverLimiter) wait(key string) time.Duration {
+    if l.config.Redis != nil {
+        return l.waitRedis(key)
+    }
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    now    := gtime.Nanosecond()
+    bucket := l.bucket(key, now)
+    bucket.tokens = math.Min(float64(l.config.Burst), bucket.tokens + float64(now - bucket.last) * l.config.Rate / 1e9)
+    bucket.last   = now
+    if bucket.tokens >= 1 {
+        bucket.tokens--
+        return 0
+    }
+    return time.Duration((1 - bucket.tokens) * 1e9 / l.config.Rate)
+}
+
+// 使用Redis令牌桶获取一个令牌，Redis不可用时不做限制
+func (l *serverLimiter) waitRedis(key string) time.Duration {
+    redis := gredis.New(*l.config.Redis)
+    defer redis.Close()
+    r, err := redis.Do("EVAL", gLIMITER_REDIS_SCRIPT, 1, l.config.RedisPrefix + l.name + ":" + key,
+        l.config.Rate, l.config.Burst, gtime.Millisecond())
+    if err != nil {
+        glog.Error("limiter redis error:", err)
+        return 0
+    }
+    return time.Duration(gconv.Int64(r)) * time.Millisecond
+}
+
+// 增加并发计数，超过最大并发数时返回false
+func (l *serverLimiter) acquire(key string) bool {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    bucket := l.bucket(key, gtime.Nanosecond())
+    if bucket.running >= l.config.Concurrency {
+        return false
+    }
+    bucket.running++
+    return true
+}
+
+// 减少并发计数
+func (l *serverLimiter) release(key string) {
+    l.mu.Lock()
+    if bucket, ok := l.buckets[key]; ok && bucket.running > 0 {
+        bucket.running--
+    }
+    l.mu.Unlock()
+}
+
+// 获取或者创建令牌桶，新建的令牌桶是满的，调用方需要加锁
+func (l *serverLimiter) bucket(key string, now int64) *limiterBucket {
+    bucket, ok := l.buckets[key]
+    if !ok {
+        bucket = &limiterBucket {
+            tokens : float64(l.config.Burst),
+            last   : now,
+        }
+        l.buckets[key] = bucket
+    }
+    return bucket
+}
+
+// 清理闲置的令牌桶(令牌已经装满，并且没有正在处理的请求)，防止内存无限增长
+func (l *serverLimiter) clearIdle() {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    now := gtime.Nanosecond()
+    for key, bucket := range l.buckets {
+        if bucket.running > 0 {
+            continue
+        }
+        if l.config.Rate == 0 || bucket.tokens + float64(now - bucket.last) * l.config.Rate / 1e9 >= float64(l.config.Burst) {
+            delete(l.buckets, key)
+        }
+    }
+}
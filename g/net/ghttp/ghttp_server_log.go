@@ -4,6 +4,8 @@
 // If a copy of the MIT was not distributed with this file,
 // You can obtain one at https://gitee.com/johng/gf.
 // 默认错误日志封装.
+// 访问日志及错误日志通过请求上下文输出，每行日志在时间之后带有"[请求ID]"前缀，
+// 格式如：2018-08-08 10:00:00.000 [请求ID] "GET host /uri HTTP/1.1" 200 ...
 
 package ghttp
 
@@ -29,7 +31,7 @@ func (s *Server) handleAccessLog(r *Request) {
     )
     content += fmt.Sprintf(` %.3f`, float64(r.LeaveTime - r.EnterTime)/1000)
     content += fmt.Sprintf(`, %s, "%s", "%s"`, r.GetClientIp(), r.Referer(), r.UserAgent())
-    s.accessLogger.Println(content)
+    s.accessLogger.Ctx(r.Context()).Println(content)
 }
 
 // 处理服务错误信息，主要是panic，http请求的status由access log进行管理
@@ -48,5 +50,5 @@ func (s *Server) handleErrorLog(error interface{}, r *Request) {
     content := fmt.Sprintf(`%v, "%s %s %s %s"`, error, r.Method, r.Host, r.URL.String(), r.Proto)
     content += fmt.Sprintf(` %.3f`, float64(r.LeaveTime - r.EnterTime)/1000)
     content += fmt.Sprintf(`, %s, "%s", "%s"`,  r.GetClientIp(), r.Referer(), r.UserAgent())
-    s.errorLogger.Error(content)
+    s.errorLogger.Ctx(r.Context()).Error(content)
 }
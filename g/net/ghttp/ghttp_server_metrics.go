@@ -0,0 +1,289 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 服务监控指标(Prometheus文本格式).
+
+package ghttp
+
+import (
+    "sort"
+    "sync"
+    "bytes"
+    "strconv"
+    "strings"
+    "gitee.com/johng/gf/g/os/gcache"
+    "gitee.com/johng/gf/g/os/grpool"
+    "gitee.com/johng/gf/g/container/gtype"
+)
+
+const (
+    gDEFAULT_METRICS_PATTERN = "/metrics"  // 默认的监控指标访问路径
+    gMETRICS_ROUTE_STATIC    = "static"    // 静态文件请求的路由标签
+    gMETRICS_ROUTE_UNMATCHED = "unmatched" // 未匹配到路由的请求标签
+)
+
+// 请求耗时分布区间上限(秒)
+var metricsDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// 返回内容大小分布区间上限(byte)
+var metricsSizeBuckets     = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+
+// 注册的外部监控指标输出方法
+var metricsCollectors = struct {
+    sync.RWMutex
+    list []func(buffer *bytes.Buffer)
+}{}
+
+// 监控指标对象
+type serverMetrics struct {
+    mu       sync.RWMutex                   // 统计项读写锁
+    inflight *gtype.Int                     // 正在处理的请求数
+    items    map[string]*metricsRequestItem // 请求统计项，键名为：路由 + 方法 + 状态码
+}
+
+// 请求统计项(按照路由规则、请求方法、返回状态码统计)
+type metricsRequestItem struct {
+    mu       sync.Mutex        // 统计数据锁
+    route    string            // 路由规则
+    method   string            // 请求方法
+    status   int               // 返回状态码
+    duration *metricsHistogram // 请求耗时分布(秒)
+    size     *metricsHistogram // 返回内容大小分布(byte)
+}
+
+// 分布统计
+type metricsHistogram struct {
+    buckets []float64 // 区间上限
+    counts  []int64   // 各区间的次数(非累计)，最后一项为超过最大区间的次数
+    count   int64     // 总次数
+    sum     float64   // 总和
+}
+
+// 开启监控指标服务，以Prometheus文本格式输出请求数、请求耗时、正在处理的请求数、返回内容大小(按照路由规则、请求方法、
+// 返回状态码统计)，以及缓存命中率、goroutine池队列长度和通过RegisterMetricsCollector注册的指标，pattern默认为/metrics。
+func (s *Server) EnableMetrics(pattern...string) error {
+    if s.metrics != nil {
+        return nil
+    }
+    p := gDEFAULT_METRICS_PATTERN
+    if len(pattern) > 0 {
+        p = pattern[0]
+    }
+    metrics := &serverMetrics {
+        inflight : gtype.NewInt(),
+        items    : make(map[string]*metricsRequestItem),
+    }
+    if err := s.BindHandler(p, metrics.serve); err != nil {
+        return err
+    }
+    s.metrics = metrics
+    return nil
+}
+
+// 注册外部监控指标输出方法(例如gdb的SQL执行统计)，监控指标服务输出时会依次调用，
+// 输出内容需为Prometheus文本格式，可使用WriteMetricsHeader、WriteMetricsHistogram、FormatMetricsLabels辅助输出
+func RegisterMetricsCollector(collector func(buffer *bytes.Buffer)) {
+    metricsCollectors.Lock()
+    metricsCollectors.list = append(metricsCollectors.list, collector)
+    metricsCollectors.Unlock()
+}
+
+// 创建分布统计对象
+func newMetricsHistogram(buckets []float64) *metricsHistogram {
+    return &metricsHistogram {
+        buckets : buckets,
+        counts  : make([]int64, len(buckets) + 1),
+    }
+}
+
+// 记录一次统计值
+func (h *metricsHistogram) observe(v float64) {
+    h.counts[sort.SearchFloat64s(h.buckets, v)]++
+    h.count++
+    h.sum += v
+}
+
+// 记录一次请求的统计数据(请求完成后调用)
+func (m *serverMetrics) observe(r *Request) {
+    route := gMETRICS_ROUTE_UNMATCHED
+    if r.Router != nil {
+        route = r.Router.Uri
+    } else if r.IsFileRequest() {
+        route = gMETRICS_ROUTE_STATIC
+    }
+    status := r.Response.Status
+    key    := route + " " + r.Method + " " + strconv.Itoa(status)
+    m.mu.RLock()
+    item, ok := m.items[key]
+    m.mu.RUnlock()
+    if !ok {
+        m.mu.Lock()
+        if item, ok = m.items[key]; !ok {
+            item = &metricsRequestItem {
+                route    : route,
+                method   : r.Method,
+                status   : status,
+                duration : newMetricsHistogram(metricsDurationBuckets),
+                size     : newMetricsHistogram(metricsSizeBuckets),
+            }
+            m.items[key] = item
+        }
+        m.mu.Unlock()
+    }
+    item.mu.Lock()
+    item.duration.observe(float64(r.LeaveTime - r.EnterTime)/1000000)
+    item.size.observe(float64(r.Response.ContentSize()))
+    item.mu.Unlock()
+}
+
+// 监控指标输出服务
+func (m *serverMetrics) serve(r *Request) {
+    buffer := bytes.NewBuffer(nil)
+    m.writeRequestMetrics(buffer)
+    writeCacheMetrics(buffer)
+    writePoolMetrics(buffer)
+    metricsCollectors.RLock()
+    collectors := metricsCollectors.list
+    metricsCollectors.RUnlock()
+    for _, collector := range collectors {
+        collector(buffer)
+    }
+    r.Response.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+    r.Response.Write(buffer.Bytes())
+}
+
+// 输出请求相关的监控指标
+func (m *serverMetrics) writeRequestMetrics(buffer *bytes.Buffer) {
+    m.mu.RLock()
+    keys := make([]string, 0, len(m.items))
+    for k, _ := range m.items {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    items := make([]*metricsRequestItem, len(keys))
+    for i, k := range keys {
+        items[i] = m.items[k]
+    }
+    m.mu.RUnlock()
+
+    WriteMetricsHeader(buffer, "ghttp_requests_inflight", "gauge", "Number of requests currently being served.")
+    buffer.WriteString("ghttp_requests_inflight " + strconv.Itoa(m.inflight.Val()) + "\n")
+
+    WriteMetricsHeader(buffer, "ghttp_requests_total", "counter", "Total number of served requests.")
+    for _, item := range items {
+        item.mu.Lock()
+        count := item.duration.count
+        item.mu.Unlock()
+        buffer.WriteString("ghttp_requests_total" + item.labels() + " " + strconv.FormatInt(count, 10) + "\n")
+    }
+    WriteMetricsHeader(buffer, "ghttp_request_duration_seconds", "histogram", "Request latency in seconds.")
+    for _, item := range items {
+        item.mu.Lock()
+        item.duration.write(buffer, "ghttp_request_duration_seconds", item.labelPairs())
+        item.mu.Unlock()
+    }
+    WriteMetricsHeader(buffer, "ghttp_response_size_bytes", "histogram", "Response size in bytes.")
+    for _, item := range items {
+        item.mu.Lock()
+        item.size.write(buffer, "ghttp_response_size_bytes", item.labelPairs())
+        item.mu.Unlock()
+    }
+}
+
+// 输出全局缓存相关的监控指标
+func writeCacheMetrics(buffer *bytes.Buffer) {
+    hits, misses := gcache.Stats()
+    ratio        := float64(0)
+    if hits + misses > 0 {
+        ratio = float64(hits)/float64(hits + misses)
+    }
+    WriteMetricsHeader(buffer, "gcache_hits_total", "counter", "Total number of global cache hits.")
+    buffer.WriteString("gcache_hits_total " + strconv.FormatInt(hits, 10) + "\n")
+    WriteMetricsHeader(buffer, "gcache_misses_total", "counter", "Total number of global cache misses.")
+    buffer.WriteString("gcache_misses_total " + strconv.FormatInt(misses, 10) + "\n")
+    WriteMetricsHeader(buffer, "gcache_hit_ratio", "gauge", "Hit ratio of the global cache.")
+    buffer.WriteString("gcache_hit_ratio " + formatMetricsFloat(ratio) + "\n")
+    WriteMetricsHeader(buffer, "gcache_size", "gauge", "Number of items in the global cache.")
+    buffer.WriteString("gcache_size " + strconv.Itoa(gcache.Size()) + "\n")
+}
+
+// 输出goroutine池相关的监控指标
+func writePoolMetrics(buffer *bytes.Buffer) {
+    WriteMetricsHeader(buffer, "grpool_workers", "gauge", "Number of running workers in the default goroutine pool.")
+    buffer.WriteString("grpool_workers " + strconv.Itoa(grpool.Size()) + "\n")
+    WriteMetricsHeader(buffer, "grpool_jobs_queued", "gauge", "Number of queued jobs in the default goroutine pool.")
+    buffer.WriteString("grpool_jobs_queued " + strconv.Itoa(grpool.Jobs()) + "\n")
+}
+
+// 请求统计项的标签键值对
+func (item *metricsRequestItem) labelPairs() []string {
+    return []string{"route", item.route, "method", item.method, "status", strconv.Itoa(item.status)}
+}
+
+// 请求统计项的标签字符串
+func (item *metricsRequestItem) labels() string {
+    return FormatMetricsLabels(item.labelPairs()...)
+}
+
+// 输出分布统计指标
+func (h *metricsHistogram) write(buffer *bytes.Buffer, name string, pairs []string) {
+    WriteMetricsHistogram(buffer, name, pairs, h.buckets, h.counts, h.count, h.sum)
+}
+
+// 输出指标的HELP及TYPE说明
+func WriteMetricsHeader(buffer *bytes.Buffer, name, mtype, help string) {
+    buffer.WriteString("# HELP " + name + " " + help + "\n")
+    buffer.WriteString("# TYPE " + name + " " + mtype + "\n")
+}
+
+// 输出分布统计指标，counts为各区间的次数(非累计)，输出时转换为累计值，count为总次数，sum为总和
+func WriteMetricsHistogram(buffer *bytes.Buffer, name string, pairs []string, buckets []float64, counts []int64, count int64, sum float64) {
+    total := int64(0)
+    for i, bound := range buckets {
+        total += counts[i]
+        buffer.WriteString(name + "_bucket" + FormatMetricsLabels(append(pairs, "le", formatMetricsFloat(bound))...) +
+            " " + strconv.FormatInt(total, 10) + "\n")
+    }
+    buffer.WriteString(name + "_bucket" + FormatMetricsLabels(append(pairs, "le", "+Inf")...) + " " + strconv.FormatInt(count, 10) + "\n")
+    buffer.WriteString(name + "_sum"    + FormatMetricsLabels(pairs...) + " " + formatMetricsFloat(sum) + "\n")
+    buffer.WriteString(name + "_count"  + FormatMetricsLabels(pairs...) + " " + strconv.FormatInt(count, 10) + "\n")
+}
+
+// 将标签键值对格式化为标签字符串，如：{route="/user",method="GET"}
+func FormatMetricsLabels(pairs...string) string {
+    if len(pairs) == 0 {
+        return ""
+    }
+    array := make([]string, 0, len(pairs)/2)
+    for i := 0; i + 1 < len(pairs); i += 2 {
+        array = append(array, pairs[i] + `="` + escapeMetricsLabel(pairs[i + 1]) + `"`)
+    }
+    return "{" + strings.Join(array, ",") + "}"
+}
+
+// 标签值转义
+func escapeMetricsLabel(value string) string {
+    return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// 浮点数格式化
+func formatMetricsFloat(value float64) string {
+    return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// 处理请求开始时的监控统计
+func (s *Server) handleMetricsEnter() {
+    if s.metrics != nil {
+        s.metrics.inflight.Add(1)
+    }
+}
+
+// 处理请求完成时的监控统计
+func (s *Server) handleMetricsLeave(r *Request) {
+    if s.metrics != nil {
+        s.metrics.inflight.Add(-1)
+        s.metrics.observe(r)
+    }
+}
@@ -0,0 +1,146 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 中间件注册及检索.
+
+package ghttp
+
+import (
+    "errors"
+    "strings"
+    "gitee.com/johng/gf/g/util/gregex"
+)
+
+const (
+    gMIDDLEWARE_HOOK_NAME   = "__middleware" // 中间件检索结果在hooksCache中的缓存键名前缀
+    gMIDDLEWARE_PATTERN_ALL = "/*"           // 全局中间件使用的路由规则
+)
+
+// 绑定中间件到指定的路由规则，pattern格式同BindHandler，可以同时给定多个中间件。
+// 中间件按照注册顺序依次执行，在中间件中通过r.Middleware.Next()调用下一个中间件或者路由服务方法，
+// 如果中间件没有调用Next()，那么后续的中间件及服务方法都不会执行。
+func (s *Server) BindMiddleware(pattern string, handlers...HandlerFunc) error {
+    if s.Status() == SERVER_STATUS_RUNNING {
+        return errors.New("cannot bind middleware while server running")
+    }
+    router, err := s.newMatchRouter(pattern)
+    if err != nil {
+        return err
+    }
+    for _, handler := range handlers {
+        s.middlewares = append(s.middlewares, &handlerItem {
+            rtype  : gROUTE_REGISTER_MIDDLEWARE,
+            faddr  : handler,
+            router : router,
+        })
+    }
+    return nil
+}
+
+// 绑定全局中间件，对所有的路由服务方法生效
+func (s *Server) BindMiddlewareDefault(handlers...HandlerFunc) error {
+    return s.BindMiddleware(gMIDDLEWARE_PATTERN_ALL, handlers...)
+}
+
+// 绑定中间件到指定域名下的路由规则
+func (d *Domain) BindMiddleware(pattern string, handlers...HandlerFunc) error {
+    for domain, _ := range d.m {
+        if err := d.s.BindMiddleware(pattern + "@" + domain, handlers...); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// 绑定域名全局中间件，对该域名下的所有路由服务方法生效
+func (d *Domain) BindMiddlewareDefault(handlers...HandlerFunc) error {
+    return d.BindMiddleware(gMIDDLEWARE_PATTERN_ALL, handlers...)
+}
+
+// 绑定中间件到分组下的路由规则，pattern会自动加上分组前缀
+func (g *RouterGroup) BindMiddleware(pattern string, handlers...HandlerFunc) error {
+    if g.domain != nil {
+        return g.domain.BindMiddleware(g.getPrefixedPattern(pattern), handlers...)
+    }
+    return g.server.BindMiddleware(g.getPrefixedPattern(pattern), handlers...)
+}
+
+// 绑定分组中间件，对该分组下的所有路由服务方法生效
+func (g *RouterGroup) Middleware(handlers...HandlerFunc) error {
+    return g.BindMiddleware(gMIDDLEWARE_PATTERN_ALL, handlers...)
+}
+
+// 查询请求匹配的中间件列表，按照注册顺序返回，并按照Host、Method、Path进行缓存
+func (s *Server) getMiddlewareHandlerWithCache(r *Request) []*handlerParsedItem {
+    if len(s.middlewares) == 0 {
+        return nil
+    }
+    cacheItems := ([]*handlerParsedItem)(nil)
+    cacheKey   := s.hookHandlerKey(gMIDDLEWARE_HOOK_NAME, r.Method, r.URL.Path, r.GetHost())
+    if v := s.hooksCache.Get(cacheKey); v == nil {
+        cacheItems = s.searchMiddlewareHandler(r.Method, r.URL.Path, r.GetHost())
+        if cacheItems != nil {
+            s.hooksCache.Set(cacheKey, cacheItems, 0)
+        }
+    } else {
+        cacheItems = v.([]*handlerParsedItem)
+    }
+    return cacheItems
+}
+
+// 中间件检索，中间件数量一般不会很多，因此直接按照注册顺序遍历匹配
+func (s *Server) searchMiddlewareHandler(method, path, domain string) []*handlerParsedItem {
+    if len(path) == 0 {
+        return nil
+    }
+    parsedItems := make([]*handlerParsedItem, 0)
+    for _, item := range s.middlewares {
+        if match := item.router.match(method, path, domain); match != nil {
+            parsedItem := &handlerParsedItem{item, nil}
+            if len(item.router.RegNames) > 0 && len(match) > len(item.router.RegNames) {
+                parsedItem.values = make(map[string][]string)
+                for i, name := range item.router.RegNames {
+                    parsedItem.values[name] = append(parsedItem.values[name], match[i + 1])
+                }
+            }
+            parsedItems = append(parsedItems, parsedItem)
+        }
+    }
+    return parsedItems
+}
+
+// 根据pattern创建用于按照注册顺序遍历匹配的路由对象(中间件、CSRF豁免规则等使用)
+func (s *Server) newMatchRouter(pattern string) (*Router, error) {
+    domain, method, uri, err := s.parsePattern(pattern)
+    if err != nil {
+        return nil, err
+    }
+    router := &Router {
+        Uri      : uri,
+        Domain   : domain,
+        Method   : method,
+        Priority : strings.Count(uri[1:], "/"),
+    }
+    router.RegRule, router.RegNames = s.patternToRegRule(uri)
+    // 根路由只匹配根路径，不做模糊匹配
+    if uri == "/" {
+        router.RegRule = "^/$"
+    }
+    return router, nil
+}
+
+// 判断路由对象是否匹配请求的Method、Path及域名，匹配时返回正则匹配结果，否则返回nil
+func (router *Router) match(method, path, domain string) []string {
+    if !strings.EqualFold(router.Domain, gDEFAULT_DOMAIN) && !strings.EqualFold(router.Domain, domain) {
+        return nil
+    }
+    if !strings.EqualFold(router.Method, gDEFAULT_METHOD) && !strings.EqualFold(router.Method, method) {
+        return nil
+    }
+    if match, err := gregex.MatchString(router.RegRule, path); err == nil && len(match) > 0 {
+        return match
+    }
+    return nil
+}
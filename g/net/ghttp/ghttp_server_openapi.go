@@ -0,0 +1,584 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// OpenAPI 3接口文档生成.
+
+package ghttp
+
+import (
+    "sync"
+    "time"
+    "reflect"
+    "strings"
+    "strconv"
+    "encoding/json"
+    "gitee.com/johng/gf/g/util/gconv"
+    "gitee.com/johng/gf/g/util/gregex"
+)
+
+const (
+    gDEFAULT_OPENAPI_PATTERN    = "/openapi.json" // 默认的OpenAPI文档路由
+    gDEFAULT_OPENAPI_UI_PATTERN = "/swagger"      // 默认的接口文档页面路由
+    gOPENAPI_VERSION            = "3.0.3"         // 生成的OpenAPI规范版本
+)
+
+// 接口文档描述
+type ApiDoc struct {
+    Summary     string      // 接口简述
+    Description string      // 接口详细描述
+    Tags        []string    // 接口分组标签，执行对象及控制器默认使用结构体名称
+    Request     interface{} // 请求参数结构体对象，属性名称按照params/json标签确定，gvalid标签生成参数约束
+    Response    interface{} // 返回数据结构体对象(JSON)，属性名称按照json标签确定
+    Deprecated  bool        // 是否已废弃
+}
+
+// 接口文档提供接口，执行对象及控制器可实现该接口，返回方法名称与接口文档的映射，
+// 注册路由时自动关联到对应方法的路由上(ApiDoc方法本身不会被注册为路由)
+type ApiDocProvider interface {
+    ApiDoc() map[string]ApiDoc
+}
+
+// OpenAPI文档基本信息
+type OpenApiInfo struct {
+    Title       string // 文档标题，默认为Server名称
+    Description string // 文档描述
+    Version     string // 接口版本，默认为1.0.0
+}
+
+// OpenAPI文档管理对象
+type serverOpenApi struct {
+    mu        sync.RWMutex       // 并发安全锁
+    info      OpenApiInfo        // 文档基本信息
+    pattern   string             // 文档路由URI(为空表示未开启)
+    uiPattern string             // 文档页面路由URI
+    docs      map[string]*ApiDoc // 通过SetApiDoc设置的接口文档，键名为domain|method|uri
+    content   []byte             // 生成的文档内容(服务运行后路由不再变化，因此只生成一次)
+}
+
+// 文档生成过程中的状态
+type openApiBuilder struct {
+    schemas map[string]interface{}  // 结构体类型对应的schema(components.schemas)
+    types   map[reflect.Type]string // 已处理的结构体类型及对应的schema名称
+    opIds   map[string]int          // 已使用的operationId(保证唯一)
+}
+
+// 创建OpenAPI文档管理对象
+func newServerOpenApi() *serverOpenApi {
+    return &serverOpenApi {
+        docs : make(map[string]*ApiDoc),
+    }
+}
+
+// 开启OpenAPI文档，pattern[0]为JSON文档的路由(默认/openapi.json)，pattern[1]为文档页面的路由(默认/swagger)，
+// 文档根据已注册的路由生成，执行对象及控制器可通过实现ApiDocProvider接口、回调函数可通过SetApiDoc方法补充接口描述及请求/返回结构。
+func (s *Server) EnableOpenApi(pattern...string) error {
+    p  := gDEFAULT_OPENAPI_PATTERN
+    ui := gDEFAULT_OPENAPI_UI_PATTERN
+    if len(pattern) > 0 && pattern[0] != "" {
+        p  = pattern[0]
+    }
+    if len(pattern) > 1 && pattern[1] != "" {
+        ui = pattern[1]
+    }
+    if err := s.BindHandler(p, s.serveOpenApi); err != nil {
+        return err
+    }
+    if err := s.BindHandler(ui, s.serveOpenApiUI); err != nil {
+        return err
+    }
+    // 只保存URI部分，用于页面请求文档及生成文档时排除文档自身的路由
+    _, _, p,  _ = s.parsePattern(p)
+    _, _, ui, _ = s.parsePattern(ui)
+    s.openapi.mu.Lock()
+    s.openapi.pattern   = p
+    s.openapi.uiPattern = ui
+    s.openapi.mu.Unlock()
+    return nil
+}
+
+// 设置OpenAPI文档基本信息
+func (s *Server) SetOpenApiInfo(info OpenApiInfo) {
+    s.openapi.mu.Lock()
+    s.openapi.info    = info
+    s.openapi.content = nil
+    s.openapi.mu.Unlock()
+}
+
+// 设置指定路由的接口文档，pattern需要与路由注册时的pattern一致(不包含方法名称的自动附加部分)，
+// 用于BindHandler注册的回调函数，也可以覆盖执行对象及控制器的接口文档
+func (s *Server) SetApiDoc(pattern string, doc ApiDoc) error {
+    domain, method, uri, err := s.parsePattern(pattern)
+    if err != nil {
+        return err
+    }
+    s.openapi.mu.Lock()
+    s.openapi.docs[openApiDocKey(domain, method, uri)] = &doc
+    s.openapi.content = nil
+    s.openapi.mu.Unlock()
+    return nil
+}
+
+// 根据已注册的路由生成OpenAPI 3文档
+func (s *Server) GetOpenApi() map[string]interface{} {
+    s.openapi.mu.RLock()
+    defer s.openapi.mu.RUnlock()
+    info := s.openapi.info
+    if info.Title == "" {
+        info.Title = s.name
+    }
+    if info.Version == "" {
+        info.Version = "1.0.0"
+    }
+    b := &openApiBuilder {
+        schemas : make(map[string]interface{}),
+        types   : make(map[reflect.Type]string),
+        opIds   : make(map[string]int),
+    }
+    paths := make(map[string]interface{})
+    for _, item := range s.getServeHandlers() {
+        switch item.rtype {
+            case gROUTE_REGISTER_HANDLER, gROUTE_REGISTER_OBJECT, gROUTE_REGISTER_CONTROLLER:
+            default:
+                continue
+        }
+        router := item.router
+        if router.Domain == gDEFAULT_DOMAIN && (router.Uri == s.openapi.pattern || router.Uri == s.openapi.uiPattern) {
+            continue
+        }
+        doc := item.doc
+        if d, ok := s.openapi.docs[openApiDocKey(router.Domain, router.Method, router.Uri)]; ok {
+            doc = d
+        }
+        path, pathParams := openApiPath(router.Uri)
+        if _, ok := paths[path]; !ok {
+            paths[path] = make(map[string]interface{})
+        }
+        methods := []string{strings.ToLower(router.Method)}
+        if router.Method == gDEFAULT_METHOD {
+            methods = []string{"get", "post"}
+        }
+        for _, method := range methods {
+            operation := b.operation(method, router, pathParams, doc)
+            if router.Domain != gDEFAULT_DOMAIN {
+                operation["x-domain"] = router.Domain
+            }
+            paths[path].(map[string]interface{})[method] = operation
+        }
+    }
+    document := map[string]interface{} {
+        "openapi" : gOPENAPI_VERSION,
+        "info"    : map[string]interface{} {
+            "title"       : info.Title,
+            "description" : info.Description,
+            "version"     : info.Version,
+        },
+        "paths"   : paths,
+    }
+    if len(b.schemas) > 0 {
+        document["components"] = map[string]interface{} {
+            "schemas" : b.schemas,
+        }
+    }
+    return document
+}
+
+// 输出OpenAPI JSON文档
+func (s *Server) serveOpenApi(r *Request) {
+    s.openapi.mu.RLock()
+    content := s.openapi.content
+    s.openapi.mu.RUnlock()
+    if content == nil {
+        b, err := json.MarshalIndent(s.GetOpenApi(), "", "    ")
+        if err != nil {
+            r.Response.WriteStatus(500, err.Error())
+            return
+        }
+        content = b
+        // 服务运行期间路由不会变化，缓存生成的文档
+        s.openapi.mu.Lock()
+        s.openapi.content = content
+        s.openapi.mu.Unlock()
+    }
+    r.Response.Header().Set("Content-Type", "application/json; charset=utf-8")
+    r.Response.Write(content)
+}
+
+// 输出接口文档页面
+func (s *Server) serveOpenApiUI(r *Request) {
+    s.openapi.mu.RLock()
+    pattern := s.openapi.pattern
+    s.openapi.mu.RUnlock()
+    r.Response.Header().Set("Content-Type", "text/html; charset=utf-8")
+    r.Response.Write(strings.Replace(gOPENAPI_UI_HTML, "{openapi}", pattern, -1))
+}
+
+// 获取执行对象/控制器指定方法的接口文档，没有标签时使用结构体名称作为标签
+func getApiDoc(obj interface{}, structName, methodName string) *ApiDoc {
+    provider, ok := obj.(ApiDocProvider)
+    if !ok {
+        return nil
+    }
+    doc, ok := provider.ApiDoc()[methodName]
+    if !ok {
+        return nil
+    }
+    if len(doc.Tags) == 0 {
+        doc.Tags = []string{structName}
+    }
+    return &doc
+}
+
+// 判断是否为ApiDocProvider接口的方法(注册执行对象及控制器时需要跳过)
+func isApiDocMethod(obj interface{}, methodName string) bool {
+    _, ok := obj.(ApiDocProvider)
+    return ok && methodName == "ApiDoc"
+}
+
+// SetApiDoc设置的接口文档键名
+func openApiDocKey(domain, method, uri string) string {
+    return strings.ToLower(domain) + "|" + strings.ToUpper(method) + "|" + uri
+}
+
+// 将路由规则转换为OpenAPI路径，:name及*name转换为{name}，匿名参数自动命名，返回路径及路径参数名称
+func openApiPath(uri string) (string, []string) {
+    if uri == "/" {
+        return uri, nil
+    }
+    names := make([]string, 0)
+    parts := strings.Split(uri[1:], "/")
+    for i, v := range parts {
+        if len(v) == 0 {
+            continue
+        }
+        switch v[0] {
+            case ':', '*':
+                name := v[1:]
+                if name == "" {
+                    name = "param" + strconv.Itoa(len(names) + 1)
+                }
+                parts[i] = "{" + name + "}"
+                names    = append(names, name)
+            default:
+                if match, _ := gregex.MatchAllString(`\{([\w\.\-]+)\}`, v); len(match) > 0 {
+                    for _, m := range match {
+                        names = append(names, m[1])
+                    }
+                }
+        }
+    }
+    return "/" + strings.Join(parts, "/"), names
+}
+
+// 生成接口操作描述
+func (b *openApiBuilder) operation(method string, router *Router, pathParams []string, doc *ApiDoc) map[string]interface{} {
+    operation := map[string]interface{} {
+        "operationId" : b.operationId(method, router),
+        "responses"   : map[string]interface{} {
+            "200" : map[string]interface{} {
+                "description" : "OK",
+            },
+        },
+    }
+    // 路径参数，请求结构体中同名属性的约束会合并到路径参数中
+    params := make([]interface{}, 0)
+    inPath := make(map[string]map[string]interface{})
+    for _, name := range pathParams {
+        param := map[string]interface{} {
+            "name"     : name,
+            "in"       : "path",
+            "required" : true,
+            "schema"   : map[string]interface{}{"type" : "string"},
+        }
+        inPath[name] = param
+        params       = append(params, param)
+    }
+    if doc != nil {
+        if doc.Summary != "" {
+            operation["summary"] = doc.Summary
+        }
+        if doc.Description != "" {
+            operation["description"] = doc.Description
+        }
+        if len(doc.Tags) > 0 {
+            operation["tags"] = doc.Tags
+        }
+        if doc.Deprecated {
+            operation["deprecated"] = true
+        }
+        if doc.Request != nil {
+            if t := openApiStructType(reflect.TypeOf(doc.Request)); t != nil {
+                hasBody  := method == "post" || method == "put" || method == "patch"
+                body     := map[string]interface{} {
+                    "type"       : "object",
+                    "properties" : make(map[string]interface{}),
+                }
+                required := make([]string, 0)
+                for _, field := range openApiFields(t, "params") {
+                    schema, isRequired := b.fieldSchema(field.field)
+                    if param, ok := inPath[field.name]; ok {
+                        param["schema"] = schema
+                        continue
+                    }
+                    if hasBody {
+                        body["properties"].(map[string]interface{})[field.name] = schema
+                        if isRequired {
+                            required = append(required, field.name)
+                        }
+                    } else {
+                        param := map[string]interface{} {
+                            "name"   : field.name,
+                            "in"     : "query",
+                            "schema" : schema,
+                        }
+                        if isRequired {
+                            param["required"] = true
+                        }
+                        params = append(params, param)
+                    }
+                }
+                if hasBody {
+                    if len(required) > 0 {
+                        body["required"] = required
+                    }
+                    operation["requestBody"] = map[string]interface{} {
+                        "content" : map[string]interface{} {
+                            "application/json"                  : map[string]interface{}{"schema" : body},
+                            "application/x-www-form-urlencoded" : map[string]interface{}{"schema" : body},
+                        },
+                    }
+                }
+            }
+        }
+        if doc.Response != nil {
+            operation["responses"] = map[string]interface{} {
+                "200" : map[string]interface{} {
+                    "description" : "OK",
+                    "content"     : map[string]interface{} {
+                        "application/json" : map[string]interface{} {
+                            "schema" : b.schema(reflect.TypeOf(doc.Response)),
+                        },
+                    },
+                },
+            }
+        }
+    }
+    if len(params) > 0 {
+        operation["parameters"] = params
+    }
+    return operation
+}
+
+// 生成唯一的operationId，优先使用路由名称
+func (b *openApiBuilder) operationId(method string, router *Router) string {
+    id := router.Name
+    if id == "" {
+        id, _ = gregex.ReplaceString(`[^\w]+`, "_", router.Uri)
+        id = strings.Trim(id, "_")
+        if id == "" {
+            id = "index"
+        }
+    }
+    id = method + "_" + id
+    b.opIds[id]++
+    if n := b.opIds[id]; n > 1 {
+        id += "_" + strconv.Itoa(n)
+    }
+    return id
+}
+
+// 结构体属性及对应的参数名称
+type openApiField struct {
+    name  string
+    field reflect.StructField
+}
+
+// 获取结构体的导出属性(展开匿名嵌套结构体)，tag为优先使用的名称标签(params或json)
+func openApiFields(t reflect.Type, tag string) []openApiField {
+    fields := make([]openApiField, 0)
+    for i := 0; i < t.NumField(); i++ {
+        field := t.Field(i)
+        if field.PkgPath != "" {
+            continue
+        }
+        if field.Anonymous {
+            if ft := openApiStructType(field.Type); ft != nil {
+                fields = append(fields, openApiFields(ft, tag)...)
+                continue
+            }
+        }
+        name := ""
+        for _, key := range []string{tag, "json"} {
+            if value := field.Tag.Get(key); value != "" {
+                name = strings.TrimSpace(strings.Split(value, ",")[0])
+                break
+            }
+        }
+        if name == "-" {
+            continue
+        }
+        if name == "" {
+            name = field.Name
+        }
+        fields = append(fields, openApiField{name, field})
+    }
+    return fields
+}
+
+// 获取结构体类型(支持指针)，非结构体时返回nil
+func openApiStructType(t reflect.Type) reflect.Type {
+    for t != nil && t.Kind() == reflect.Ptr {
+        t = t.Elem()
+    }
+    if t == nil || t.Kind() != reflect.Struct {
+        return nil
+    }
+    return t
+}
+
+// 生成结构体属性的schema，并根据gvalid标签添加约束，返回是否为必需参数
+func (b *openApiBuilder) fieldSchema(field reflect.StructField) (map[string]interface{}, bool) {
+    schema   := b.schema(field.Type)
+    required := false
+    tag      := field.Tag.Get("gvalid")
+    if tag == "" {
+        return schema, required
+    }
+    // gvalid标签格式：[别名@]规则[#错误提示]
+    match, _ := gregex.MatchString(`\s*((\w+)\s*@){0,1}\s*([^#]+)\s*(#\s*(.*)){0,1}\s*`, tag)
+    if len(match) < 4 {
+        return schema, required
+    }
+    if _, ok := schema["$ref"]; ok {
+        // $ref不能与其他属性并列
+        schema = map[string]interface{}{"allOf" : []interface{}{schema}}
+    }
+    schema["x-gvalid"] = strings.TrimSpace(match[3])
+    for _, rule := range strings.Split(strings.TrimSpace(match[3]), "|") {
+        name  := rule
+        value := ""
+        if pos := strings.Index(rule, ":"); pos != -1 {
+            name  = rule[:pos]
+            value = rule[pos + 1:]
+        }
+        array := strings.Split(value, ",")
+        switch strings.TrimSpace(name) {
+            case "required":
+                required = true
+            case "integer":
+                schema["type"] = "integer"
+            case "float":
+                schema["type"] = "number"
+            case "boolean":
+                schema["type"] = "boolean"
+            case "email":
+                schema["format"] = "email"
+            case "url":
+                schema["format"] = "uri"
+            case "date":
+                schema["format"] = "date"
+            case "ip", "ipv4":
+                schema["format"] = "ipv4"
+            case "ipv6":
+                schema["format"] = "ipv6"
+            case "length":
+                if len(array) == 2 {
+                    schema["minLength"] = gconv.Int(array[0])
+                    schema["maxLength"] = gconv.Int(array[1])
+                }
+            case "min-length":
+                schema["minLength"] = gconv.Int(value)
+            case "max-length":
+                schema["maxLength"] = gconv.Int(value)
+            case "between":
+                if len(array) == 2 {
+                    schema["minimum"] = gconv.Float64(array[0])
+                    schema["maximum"] = gconv.Float64(array[1])
+                }
+            case "min":
+                schema["minimum"] = gconv.Float64(value)
+            case "max":
+                schema["maximum"] = gconv.Float64(value)
+            case "in":
+                enum := make([]interface{}, 0, len(array))
+                for _, v := range array {
+                    enum = append(enum, strings.TrimSpace(v))
+                }
+                schema["enum"] = enum
+            case "regex":
+                schema["pattern"] = value
+        }
+    }
+    return schema, required
+}
+
+// 根据Go类型生成schema，命名结构体生成到components.schemas中并返回引用
+func (b *openApiBuilder) schema(t reflect.Type) map[string]interface{} {
+    for t.Kind() == reflect.Ptr {
+        t = t.Elem()
+    }
+    if t == reflect.TypeOf(time.Time{}) {
+        return map[string]interface{}{"type" : "string", "format" : "date-time"}
+    }
+    switch t.Kind() {
+        case reflect.Bool:
+            return map[string]interface{}{"type" : "boolean"}
+        case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+            return map[string]interface{}{"type" : "integer", "format" : "int32"}
+        case reflect.Int64, reflect.Uint64:
+            return map[string]interface{}{"type" : "integer", "format" : "int64"}
+        case reflect.Float32:
+            return map[string]interface{}{"type" : "number", "format" : "float"}
+        case reflect.Float64:
+            return map[string]interface{}{"type" : "number", "format" : "double"}
+        case reflect.String:
+            return map[string]interface{}{"type" : "string"}
+        case reflect.Slice, reflect.Array:
+            if t.Elem().Kind() == reflect.Uint8 {
+                return map[string]interface{}{"type" : "string", "format" : "byte"}
+            }
+            return map[string]interface{}{"type" : "array", "items" : b.schema(t.Elem())}
+        case reflect.Map:
+            return map[string]interface{}{"type" : "object", "additionalProperties" : b.schema(t.Elem())}
+        case reflect.Struct:
+            if t.Name() == "" {
+                return b.structSchema(t)
+            }
+            name, ok := b.types[t]
+            if !ok {
+                name = t.Name()
+                // 不同包的同名结构体使用数字后缀区分
+                for i := 2; b.schemas[name] != nil; i++ {
+                    name = t.Name() + strconv.Itoa(i)
+                }
+                b.types[t]      = name
+                // 先占位，防止结构体自引用时无限递归
+                b.schemas[name] = map[string]interface{}{}
+                b.schemas[name] = b.structSchema(t)
+            }
+            return map[string]interface{}{"$ref" : "#/components/schemas/" + name}
+    }
+    return map[string]interface{}{}
+}
+
+// 生成结构体的schema
+func (b *openApiBuilder) structSchema(t reflect.Type) map[string]interface{} {
+    properties := make(map[string]interface{})
+    required   := make([]string, 0)
+    for _, field := range openApiFields(t, "json") {
+        schema, isRequired := b.fieldSchema(field.field)
+        properties[field.name] = schema
+        if isRequired {
+            required = append(required, field.name)
+        }
+    }
+    schema := map[string]interface{} {
+        "type"       : "object",
+        "properties" : properties,
+    }
+    if len(required) > 0 {
+        schema["required"] = required
+    }
+    return schema
+}
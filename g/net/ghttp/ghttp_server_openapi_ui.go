@@ -0,0 +1,172 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// OpenAPI接口文档页面(内置，不依赖外部资源).
+
+package ghttp
+
+// 接口文档页面模板，{openapi}会被替换为JSON文档的路由
+const gOPENAPI_UI_HTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>API Documentation</title>
+<style>
+body{font-family:-apple-system,"Segoe UI",Helvetica,Arial,sans-serif;margin:0;background:#f6f8fa;color:#24292e}
+header{background:#24292e;color:#fff;padding:16px 32px}
+header h1{margin:0;font-size:22px}
+header p{margin:4px 0 0;color:#c8c8c8}
+main{padding:16px 32px}
+h2{font-size:18px;border-bottom:1px solid #ddd;padding-bottom:4px;margin-top:24px}
+.op{background:#fff;border:1px solid #ddd;border-radius:4px;margin:8px 0}
+.op summary{cursor:pointer;padding:8px 12px;list-style:none}
+.op .body{padding:0 12px 12px}
+.method{display:inline-block;min-width:64px;text-align:center;color:#fff;border-radius:3px;font-weight:bold;font-size:12px;padding:3px 0;margin-right:8px}
+.get{background:#61affe}.post{background:#49cc90}.put{background:#fca130}.delete{background:#f93e3e}.patch{background:#50e3c2}.head,.options,.trace,.connect{background:#9012fe}
+.path{font-family:monospace;font-size:14px}
+.deprecated .path{text-decoration:line-through}
+.desc{color:#666;margin-left:8px}
+table{border-collapse:collapse;width:100%;font-size:13px}
+th,td{text-align:left;border-bottom:1px solid #eee;padding:4px 6px;vertical-align:top}
+pre{background:#f6f8fa;padding:8px;overflow:auto;font-size:12px}
+.req{color:#f93e3e}
+</style>
+</head>
+<body>
+<header><h1 id="title">API Documentation</h1><p id="info"></p></header>
+<main id="main">Loading...</main>
+<script>
+(function () {
+    var doc;
+    function esc(s) {
+        return String(s === undefined ? "" : s).replace(/[&<>"]/g, function (c) {
+            return {"&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;"}[c];
+        });
+    }
+    function resolve(schema) {
+        if (schema && schema.$ref) {
+            return doc.components.schemas[schema.$ref.replace("#/components/schemas/", "")] || {};
+        }
+        if (schema && schema.allOf) {
+            return resolve(schema.allOf[0]);
+        }
+        return schema || {};
+    }
+    function typeOf(schema) {
+        if (!schema) {
+            return "";
+        }
+        if (schema.$ref) {
+            return schema.$ref.replace("#/components/schemas/", "");
+        }
+        if (schema.allOf) {
+            return typeOf(schema.allOf[0]);
+        }
+        if (schema.type === "array") {
+            return typeOf(schema.items) + "[]";
+        }
+        return (schema.type || "any") + (schema.format ? "(" + schema.format + ")" : "");
+    }
+    function rules(schema) {
+        var list = [];
+        ["minLength", "maxLength", "minimum", "maximum", "pattern", "enum"].forEach(function (k) {
+            if (schema[k] !== undefined) {
+                list.push(k + ": " + schema[k]);
+            }
+        });
+        return list.join(", ");
+    }
+    function example(schema, depth) {
+        schema = schema || {};
+        if (depth > 5) {
+            return null;
+        }
+        if (schema.$ref || schema.allOf) {
+            return example(resolve(schema), depth + 1);
+        }
+        switch (schema.type) {
+            case "object":
+                var o = {};
+                for (var k in (schema.properties || {})) {
+                    o[k] = example(schema.properties[k], depth + 1);
+                }
+                return o;
+            case "array":   return [example(schema.items, depth + 1)];
+            case "integer": return 0;
+            case "number":  return 0.0;
+            case "boolean": return false;
+            case "string":  return schema.enum ? schema.enum[0] : (schema.format || "string");
+        }
+        return null;
+    }
+    function fields(rows) {
+        if (!rows.length) {
+            return "";
+        }
+        var html = "<table><tr><th>Name</th><th>In</th><th>Type</th><th>Rules</th></tr>";
+        rows.forEach(function (r) {
+            html += "<tr><td>" + esc(r.name) + (r.required ? ' <span class="req">*</span>' : "") + "</td><td>" +
+                esc(r.in) + "</td><td>" + esc(typeOf(r.schema)) + "</td><td>" + esc(rules(resolve(r.schema))) + "</td></tr>";
+        });
+        return html + "</table>";
+    }
+    function operation(path, method, op) {
+        var rows = (op.parameters || []).slice();
+        if (op.requestBody) {
+            var body = resolve(op.requestBody.content["application/json"].schema);
+            for (var k in (body.properties || {})) {
+                rows.push({name: k, in: "body", schema: body.properties[k], required: (body.required || []).indexOf(k) >= 0});
+            }
+        }
+        var html = '<details class="op' + (op.deprecated ? " deprecated" : "") + '"><summary><span class="method ' + method + '">' +
+            method.toUpperCase() + '</span><span class="path">' + esc(path) + '</span><span class="desc">' + esc(op.summary) +
+            (op["x-domain"] ? " @" + esc(op["x-domain"]) : "") + '</span></summary><div class="body">';
+        if (op.description) {
+            html += "<p>" + esc(op.description) + "</p>";
+        }
+        html += fields(rows);
+        var resp = op.responses["200"];
+        if (resp && resp.content) {
+            html += "<p>Response:</p><pre>" + esc(JSON.stringify(example(resp.content["application/json"].schema, 0), null, 2)) + "</pre>";
+        }
+        return html + "</div></details>";
+    }
+    function render() {
+        document.title = doc.info.title;
+        document.getElementById("title").textContent = doc.info.title + " " + doc.info.version;
+        document.getElementById("info").textContent  = doc.info.description || "";
+        var groups = {}, names = [];
+        Object.keys(doc.paths).sort().forEach(function (path) {
+            for (var method in doc.paths[path]) {
+                var op  = doc.paths[path][method];
+                var tag = (op.tags && op.tags[0]) || "default";
+                if (!groups[tag]) {
+                    groups[tag] = [];
+                    names.push(tag);
+                }
+                groups[tag].push(operation(path, method, op));
+            }
+        });
+        var html = "";
+        names.sort().forEach(function (tag) {
+            html += "<h2>" + esc(tag) + "</h2>" + groups[tag].join("");
+        });
+        document.getElementById("main").innerHTML = html || "No routes.";
+    }
+    var xhr = new XMLHttpRequest();
+    xhr.open("GET", "{openapi}");
+    xhr.onload = function () {
+        try {
+            doc = JSON.parse(xhr.responseText);
+            render();
+        } catch (e) {
+            document.getElementById("main").textContent = "Failed to load document: " + e;
+        }
+    };
+    xhr.send();
+})();
+</script>
+</body>
+</html>`
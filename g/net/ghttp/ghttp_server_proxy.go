@@ -0,0 +1,333 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 反向代理及本地代理(路由映射).
+
+package ghttp
+
+import (
+    "net"
+    "sync"
+    "time"
+    "bufio"
+    "errors"
+    "path"
+    "strings"
+    "net/url"
+    "net/http"
+    "net/http/httputil"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/util/gconv"
+    "gitee.com/johng/gf/g/util/gregex"
+)
+
+const (
+    PROXY_BALANCE_ROUND_ROBIN    = 0                // 负载均衡策略：轮询
+    PROXY_BALANCE_WEIGHT         = 1                // 负载均衡策略：加权轮询(平滑加权，同nginx)
+    gDEFAULT_PROXY_DIAL_TIMEOUT  = 10 * time.Second // 默认的上游连接超时时间
+)
+
+// 反向代理配置
+type ProxyConfig struct {
+    Upstreams       []string          // 上游服务地址列表，如：http://127.0.0.1:8080，加权轮询时可以使用"#权重"后缀，如：http://127.0.0.1:8080#3
+    Balance         int               // 负载均衡策略，默认为轮询
+    StripPrefix     string            // 转发到上游前需要从URI中去掉的前缀
+    PreserveHost    bool              // 是否将客户端请求的Host转发给上游(默认使用上游地址的Host)
+    RequestHeaders  map[string]string // 转发到上游时设置的请求Header，键值为空时表示删除该Header
+    ResponseHeaders map[string]string // 返回给客户端时设置的返回Header，键值为空时表示删除该Header
+    DialTimeout     time.Duration     // 连接上游的超时时间
+    ResponseTimeout time.Duration     // 等待上游返回Header的超时时间(不包含WebSocket等长连接的数据传输时间)
+}
+
+// 上游服务
+type proxyUpstream struct {
+    url     *url.URL // 上游地址
+    weight  int      // 权重
+    current int      // 平滑加权轮询的当前权重
+}
+
+// 反向代理对象
+type serverProxy struct {
+    mu        sync.Mutex       // 负载均衡选择互斥锁
+    index     int              // 轮询索引
+    config    ProxyConfig      // 代理配置
+    upstreams []*proxyUpstream // 上游服务列表
+    transport *http.Transport  // 共享的底层连接池
+}
+
+// 用于代理输出的ResponseWriter，直接输出到客户端(不经过缓冲区)，并记录返回状态码供日志使用，
+// 同时支持Hijack及Flush，以便WebSocket及流式数据透传
+type proxyResponseWriter struct {
+    http.ResponseWriter
+    response *Response
+}
+
+// 绑定反向代理，将匹配pattern的请求转发到上游服务，pattern格式同BindHandler
+func (s *Server) BindProxy(pattern string, config ProxyConfig) error {
+    p, err := newServerProxy(config)
+    if err != nil {
+        return err
+    }
+    return s.bindHandlerItem(pattern, &handlerItem {
+        rtype : gROUTE_REGISTER_PROXY,
+        faddr : p.serve,
+    })
+}
+
+// 绑定本地代理，将匹配pattern的请求在服务内部重新分发到target对应的路由服务方法，
+// target中可以使用{name}引用pattern匹配到的路由参数，例如：BindProxyLocal("/u/:id", "/user/{id}/profile")
+func (s *Server) BindProxyLocal(pattern string, target string) error {
+    if len(target) == 0 || target[0] != '/' {
+        return errors.New(`invalid local proxy target "` + target + `"`)
+    }
+    return s.bindHandlerItem(pattern, &handlerItem {
+        rtype  : gROUTE_REGISTER_REWRITE,
+        target : target,
+    })
+}
+
+// 绑定域名下的反向代理
+func (d *Domain) BindProxy(pattern string, config ProxyConfig) error {
+    for domain, _ := range d.m {
+        if err := d.s.BindProxy(pattern + "@" + domain, config); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// 绑定域名下的本地代理
+func (d *Domain) BindProxyLocal(pattern string, target string) error {
+    for domain, _ := range d.m {
+        if err := d.s.BindProxyLocal(pattern + "@" + domain, target); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// 绑定分组下的反向代理
+func (g *RouterGroup) BindProxy(pattern string, config ProxyConfig) error {
+    if g.domain != nil {
+        return g.domain.BindProxy(g.getPrefixedPattern(pattern), config)
+    }
+    return g.server.BindProxy(g.getPrefixedPattern(pattern), config)
+}
+
+// 绑定分组下的本地代理，注意target不会自动加上分组前缀
+func (g *RouterGroup) BindProxyLocal(pattern string, target string) error {
+    if g.domain != nil {
+        return g.domain.BindProxyLocal(g.getPrefixedPattern(pattern), target)
+    }
+    return g.server.BindProxyLocal(g.getPrefixedPattern(pattern), target)
+}
+
+// 创建反向代理对象
+func newServerProxy(config ProxyConfig) (*serverProxy, error) {
+    if len(config.Upstreams) == 0 {
+        return nil, errors.New("proxy upstreams cannot be empty")
+    }
+    p := &serverProxy {
+        config    : config,
+        upstreams : make([]*proxyUpstream, 0, len(config.Upstreams)),
+    }
+    for _, v := range config.Upstreams {
+        weight := 1
+        array  := strings.Split(strings.TrimSpace(v), "#")
+        if len(array) > 1 {
+            if weight = gconv.Int(array[1]); weight < 1 {
+                weight = 1
+            }
+        }
+        u, err := url.Parse(array[0])
+        if err != nil {
+            return nil, err
+        }
+        if u.Scheme == "" || u.Host == "" {
+            return nil, errors.New(`invalid proxy upstream "` + v + `"`)
+        }
+        p.upstreams = append(p.upstreams, &proxyUpstream {
+            url    : u,
+            weight : weight,
+        })
+    }
+    dialTimeout := config.DialTimeout
+    if dialTimeout == 0 {
+        dialTimeout = gDEFAULT_PROXY_DIAL_TIMEOUT
+    }
+    p.transport = &http.Transport {
+        Proxy                 : http.ProxyFromEnvironment,
+        DialContext           : (&net.Dialer{ Timeout : dialTimeout }).DialContext,
+        ResponseHeaderTimeout : config.ResponseTimeout,
+        IdleConnTimeout       : 90 * time.Second,
+        MaxIdleConnsPerHost   : 32,
+    }
+    return p, nil
+}
+
+// 按照负载均衡策略选择一个上游服务
+func (p *serverProxy) next() *proxyUpstream {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    if len(p.upstreams) == 1 {
+        return p.upstreams[0]
+    }
+    if p.config.Balance == PROXY_BALANCE_WEIGHT {
+        // 平滑加权轮询：每次所有节点的当前权重加上自身权重，选择当前权重最大的节点，并将其当前权重减去总权重
+        total    := 0
+        selected := (*proxyUpstream)(nil)
+        for _, u := range p.upstreams {
+            u.current += u.weight
+            total     += u.weight
+            if selected == nil || u.current > selected.current {
+                selected = u
+            }
+        }
+        selected.current -= total
+        return selected
+    }
+    u      := p.upstreams[p.index % len(p.upstreams)]
+    p.index = (p.index + 1) % len(p.upstreams)
+    return u
+}
+
+// 执行反向代理请求
+func (p *serverProxy) serve(r *Request) {
+    upstream := p.next()
+    proxy    := &httputil.ReverseProxy {
+        Transport     : p.transport,
+        FlushInterval : 100 * time.Millisecond,
+        Director      : func(req *http.Request) {
+            path := req.URL.Path
+            if len(p.config.StripPrefix) > 0 {
+                path = strings.TrimPrefix(path, p.config.StripPrefix)
+            }
+            req.URL.Scheme   = upstream.url.Scheme
+            req.URL.Host     = upstream.url.Host
+            req.URL.Path     = joinProxyPath(upstream.url.Path, path)
+            req.URL.RawPath  = ""
+            if upstream.url.RawQuery != "" {
+                if req.URL.RawQuery == "" {
+                    req.URL.RawQuery = upstream.url.RawQuery
+                } else {
+                    req.URL.RawQuery = upstream.url.RawQuery + "&" + req.URL.RawQuery
+                }
+            }
+            if !p.config.PreserveHost {
+                req.Host = upstream.url.Host
+            }
+            // 向上游服务传递请求ID及链路追踪上下文
+            req.Header.Set(gHEADER_REQUEST_ID,   r.GetRequestId())
+            req.Header.Set(gHEADER_TRACE_PARENT, r.GetTraceParent())
+            for k, v := range p.config.RequestHeaders {
+                if v == "" {
+                    req.Header.Del(k)
+                } else {
+                    req.Header.Set(k, v)
+                }
+            }
+        },
+        ModifyResponse : func(resp *http.Response) error {
+            for k, v := range p.config.ResponseHeaders {
+                if v == "" {
+                    resp.Header.Del(k)
+                } else {
+                    resp.Header.Set(k, v)
+                }
+            }
+            return nil
+        },
+        ErrorHandler : func(w http.ResponseWriter, req *http.Request, err error) {
+            glog.Errorfln(`proxy to "%s" error: %v`, upstream.url.String(), err)
+            r.Response.WriteStatus(http.StatusBadGateway)
+        },
+    }
+    w := &proxyResponseWriter {
+        ResponseWriter : r.Response.ResponseWriter.ResponseWriter,
+        response       : r.Response,
+    }
+    proxy.ServeHTTP(w, &r.Request)
+}
+
+// 解析本地代理，将请求URI改写为target对应的路径，并返回目标路由服务方法。
+// 本地代理在执行事件回调及中间件之前解析，因此目标路由的事件回调(包括DenyRoutes)及中间件与直接请求目标路由时一致；
+// 替换路由参数后的路径会经过path.Clean处理，并且必须仍然位于target的固定前缀之下，防止通过路由参数中的".."访问其他路由；
+// 无法解析时直接设置返回状态码并返回nil。
+func (s *Server) resolveLocalProxy(r *Request, h *handlerItem) *handlerItem {
+    target  := h.target
+    p, _    := gregex.ReplaceStringFunc(`\{[\w\.\-]+\}`, target, func(v string) string {
+        return r.GetRouterString(v[1 : len(v) - 1])
+    })
+    p = path.Clean("/" + p)
+    // target中第一个路由参数之前的目录即为固定前缀
+    prefix := target
+    if pos := strings.Index(target, "{"); pos != -1 {
+        prefix = target[0 : strings.LastIndex(target[0 : pos], "/") + 1]
+    }
+    if p != path.Clean(prefix) && !strings.HasPrefix(p, strings.TrimRight(prefix, "/") + "/") {
+        r.Response.WriteStatus(http.StatusBadRequest)
+        return nil
+    }
+    r.URL.Path = p
+    parsedItem := s.getServeHandlerWithCache(r)
+    if parsedItem == nil {
+        r.Response.WriteStatus(http.StatusNotFound)
+        return nil
+    }
+    // 防止本地代理之间相互映射导致死循环
+    if parsedItem.handler.rtype == gROUTE_REGISTER_REWRITE {
+        glog.Errorfln(`local proxy target "%s" cannot be another local proxy`, p)
+        r.Response.WriteStatus(http.StatusInternalServerError)
+        return nil
+    }
+    r.routerVars = make(map[string][]string)
+    for k, v := range parsedItem.values {
+        r.routerVars[k] = v
+    }
+    r.Router = parsedItem.handler.router
+    return parsedItem.handler
+}
+
+// 拼接上游地址的路径与请求路径
+func joinProxyPath(a, b string) string {
+    aslash := strings.HasSuffix(a, "/")
+    bslash := strings.HasPrefix(b, "/")
+    switch {
+        case aslash && bslash:
+            return a + b[1:]
+        case !aslash && !bslash:
+            return a + "/" + b
+    }
+    return a + b
+}
+
+// 记录返回状态码并直接输出到客户端
+func (w *proxyResponseWriter) WriteHeader(code int) {
+    w.response.Status = code
+    w.ResponseWriter.WriteHeader(code)
+}
+
+// 直接输出到客户端，并记录输出内容大小
+func (w *proxyResponseWriter) Write(buffer []byte) (int, error) {
+    n, err := w.ResponseWriter.Write(buffer)
+    w.response.length += n
+    return n, err
+}
+
+// 刷新输出缓冲区(流式数据透传)
+func (w *proxyResponseWriter) Flush() {
+    if f, ok := w.ResponseWriter.(http.Flusher); ok {
+        f.Flush()
+    }
+}
+
+// 接管底层连接(WebSocket透传)
+func (w *proxyResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+    if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+        w.response.Status = http.StatusSwitchingProtocols
+        return h.Hijack()
+    }
+    return nil, nil, errors.New("underlying response writer does not support hijacking")
+}
@@ -15,6 +15,7 @@ import (
     "gitee.com/johng/gf/g/util/gstr"
     "gitee.com/johng/gf/g/os/glog"
     "fmt"
+    "path"
     "runtime"
 )
 
@@ -42,14 +43,19 @@ func (s *Server)parsePattern(pattern string) (domain, method, uri string, err er
     return
 }
 
-// 获得服务注册的文件地址信息
-func (s *Server) getHandlerRegisterCallerLine(handler *handlerItem) string {
-    skip := 5
-    if handler.rtype == gROUTE_REGISTER_HANDLER {
-       skip = 4
-    }
-    if _, cfile, cline, ok := runtime.Caller(skip); ok {
-        return fmt.Sprintf("%s:%d", cfile, cline)
+// 获得服务注册的文件地址信息，
+// 由于注册方法可能经过Domain、RouterGroup等多层封装，因此跳过ghttp包内部的调用层级，返回开发者实际注册的调用位置
+func (s *Server) getHandlerRegisterCallerLine() string {
+    _, pkgFile, _, _ := runtime.Caller(0)
+    pkgDir := path.Dir(pkgFile)
+    for i := 1; ; i++ {
+        _, cfile, cline, ok := runtime.Caller(i)
+        if !ok {
+            break
+        }
+        if path.Dir(cfile) != pkgDir {
+            return fmt.Sprintf("%s:%d", cfile, cline)
+        }
     }
     return ""
 }
@@ -70,7 +76,7 @@ func (s *Server) setHandler(pattern string, handler *handlerItem, hook ... strin
         return errors.New("invalid pattern")
     }
     regkey := s.hookHandlerKey(hookName, method, uri, domain)
-    caller := s.getHandlerRegisterCallerLine(handler)
+    caller := s.getHandlerRegisterCallerLine()
     if line, ok := s.routesMap[regkey]; ok {
         s := fmt.Sprintf(`duplicated route registry "%s" in %s , former in %s`, pattern, caller, line)
         glog.Errorfln(s)
@@ -91,6 +97,14 @@ func (s *Server) setHandler(pattern string, handler *handlerItem, hook ... strin
         Priority : strings.Count(uri[1:], "/"),
     }
     handler.router.RegRule, handler.router.RegNames = s.patternToRegRule(uri)
+    handler.source = caller
+    // 命名路由
+    if len(handler.rname) > 0 {
+        if err := s.setRouteName(handler.rname, handler.router); err != nil {
+            glog.Error(err)
+            return err
+        }
+    }
 
     // 动态注册，首先需要判断是否是动态注册，如果不是那么就没必要添加到动态注册记录变量中。
     // 非叶节点为哈希表检索节点，按照URI注册的层级进行高效检索，直至到叶子链表节点；
@@ -0,0 +1,192 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 路由表查看及冲突诊断.
+
+package ghttp
+
+import (
+    "fmt"
+    "sort"
+    "bytes"
+    "strings"
+    "container/list"
+    "text/tabwriter"
+)
+
+// 路由诊断时用于替换路由参数的示例值(避免与静态路由冲突)
+const gROUTE_SAMPLE_VALUE = "GF_ROUTE_SAMPLE"
+
+// 路由表项
+type RouterItem struct {
+    Domain     string   // 域名
+    Method     string   // HTTP Method
+    Pattern    string   // 路由规则(URI)
+    Name       string   // 路由名称
+    Priority   int      // 优先级(URI层级)
+    Type       string   // 注册方式：handler/object/controller/proxy/rewrite
+    Hooks      []string // 会被执行的事件回调，格式为：事件名称:路由规则
+    Source     string   // 注册路由的源码位置(文件:行号)
+    ShadowedBy string   // 覆盖该路由的更高优先级路由(格式同Source)，为空表示未被覆盖
+}
+
+// 获取路由表，按照域名、路由规则及HTTP Method排序。
+// 对于每一条路由，使用示例值替换路由参数后按照实际的路由检索逻辑进行匹配，
+// 匹配到其他路由时表示该路由(至少部分请求)被更高优先级的路由覆盖，通过ShadowedBy返回。
+func (s *Server) GetRoutes() []RouterItem {
+    items := make([]RouterItem, 0)
+    hooks := s.getHookNames()
+    for _, item := range s.getServeHandlers() {
+        router := item.router
+        method := router.Method
+        if method == gDEFAULT_METHOD {
+            method = "GET"
+        }
+        path := routeSamplePath(router.Uri)
+        ri   := RouterItem {
+            Domain   : router.Domain,
+            Method   : router.Method,
+            Pattern  : router.Uri,
+            Name     : router.Name,
+            Priority : router.Priority,
+            Type     : routeTypeName(item.rtype),
+            Hooks    : make([]string, 0),
+            Source   : item.source,
+        }
+        if parsedItem := s.searchServeHandler(method, path, router.Domain); parsedItem != nil && parsedItem.handler != item {
+            shadow       := parsedItem.handler.router
+            ri.ShadowedBy = shadow.Method + ":" + shadow.Uri + "@" + shadow.Domain
+            if parsedItem.handler.source != "" {
+                ri.ShadowedBy += " (" + parsedItem.handler.source + ")"
+            }
+        }
+        for _, hook := range hooks {
+            for _, hookItem := range s.searchHookHandler(method, path, router.Domain, hook) {
+                ri.Hooks = append(ri.Hooks, hook + ":" + hookItem.handler.router.Uri)
+            }
+        }
+        items = append(items, ri)
+    }
+    return items
+}
+
+// 以表格形式输出路由表到标准输出，被覆盖的路由以"!"标记
+func (s *Server) DumpRoutes() {
+    fmt.Print(s.formatRoutes())
+}
+
+// 生成路由表文本
+func (s *Server) formatRoutes() string {
+    routes := s.GetRoutes()
+    buffer := bytes.NewBuffer(nil)
+    writer := tabwriter.NewWriter(buffer, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(writer, "SERVER\tDOMAIN\tMETHOD\tPATTERN\tPRIORITY\tTYPE\tHOOKS\tSOURCE\t")
+    for _, item := range routes {
+        pattern := item.Pattern
+        if item.ShadowedBy != "" {
+            pattern = "!" + pattern
+        }
+        fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t\n",
+            s.name, item.Domain, item.Method, pattern, item.Priority, item.Type, strings.Join(item.Hooks, ","), item.Source,
+        )
+    }
+    writer.Flush()
+    for _, item := range routes {
+        if item.ShadowedBy != "" {
+            fmt.Fprintf(buffer, "! %s:%s@%s is shadowed by %s\n", item.Method, item.Pattern, item.Domain, item.ShadowedBy)
+        }
+    }
+    return buffer.String()
+}
+
+// 获取所有注册的服务路由项(不包含事件回调及中间件)，按照域名、URI及HTTP Method排序
+func (s *Server) getServeHandlers() []*handlerItem {
+    items := make([]*handlerItem, 0)
+    exist := make(map[*handlerItem]struct{})
+    var walk func(m map[string]interface{})
+    walk = func(m map[string]interface{}) {
+        for k, v := range m {
+            if k == "*list" {
+                for e := v.(*list.List).Front(); e != nil; e = e.Next() {
+                    item := e.Value.(*handlerItem)
+                    if _, ok := exist[item]; !ok {
+                        exist[item] = struct{}{}
+                        items       = append(items, item)
+                    }
+                }
+            } else if sub, ok := v.(map[string]interface{}); ok {
+                walk(sub)
+            }
+        }
+    }
+    walk(s.serveTree)
+    sort.Slice(items, func(i, j int) bool {
+        a, b := items[i].router, items[j].router
+        if a.Domain != b.Domain {
+            return a.Domain < b.Domain
+        }
+        if a.Uri != b.Uri {
+            return a.Uri < b.Uri
+        }
+        return a.Method < b.Method
+    })
+    return items
+}
+
+// 获取所有注册的事件名称
+func (s *Server) getHookNames() []string {
+    names := make([]string, 0)
+    exist := make(map[string]struct{})
+    for _, v := range s.hooksTree {
+        for name, _ := range v.(map[string]interface{}) {
+            if _, ok := exist[name]; !ok {
+                exist[name] = struct{}{}
+                names       = append(names, name)
+            }
+        }
+    }
+    sort.Strings(names)
+    return names
+}
+
+// 使用示例值替换路由规则中的参数，生成可以被该路由匹配的请求路径
+func routeSamplePath(uri string) string {
+    if uri == "/" {
+        return uri
+    }
+    parts := strings.Split(uri[1:], "/")
+    for i, v := range parts {
+        if len(v) == 0 {
+            continue
+        }
+        switch v[0] {
+            case ':', '*':
+                parts[i] = gROUTE_SAMPLE_VALUE
+            default:
+                for {
+                    start := strings.Index(v, "{")
+                    end   := strings.Index(v, "}")
+                    if start == -1 || end < start {
+                        break
+                    }
+                    v = v[:start] + gROUTE_SAMPLE_VALUE + v[end + 1:]
+                }
+                parts[i] = strings.Replace(v, "*", gROUTE_SAMPLE_VALUE, -1)
+        }
+    }
+    return "/" + strings.Join(parts, "/")
+}
+
+// 路由注册方式名称
+func routeTypeName(rtype int) string {
+    switch rtype {
+        case gROUTE_REGISTER_HANDLER:    return "handler"
+        case gROUTE_REGISTER_OBJECT:     return "object"
+        case gROUTE_REGISTER_CONTROLLER: return "controller"
+        case gROUTE_REGISTER_PROXY:      return "proxy"
+        case gROUTE_REGISTER_REWRITE:    return "rewrite"
+    }
+    return "unknown"
+}
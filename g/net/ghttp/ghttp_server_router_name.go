@@ -0,0 +1,124 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 命名路由及反向URL生成.
+
+package ghttp
+
+import (
+    "errors"
+    "strings"
+    "net/url"
+    "gitee.com/johng/gf/g/util/gconv"
+    "gitee.com/johng/gf/g/util/gregex"
+)
+
+// 注册路由名称，同一名称只能对应同一个URI(允许不同的HTTP Method及域名，例如RESTful注册)
+func (s *Server) setRouteName(name string, router *Router) error {
+    s.rnmu.Lock()
+    defer s.rnmu.Unlock()
+    if r, ok := s.routeNames[name]; ok && r.Uri != router.Uri {
+        return errors.New(`duplicated route name "` + name + `", former uri "` + r.Uri + `"`)
+    }
+    router.Name        = name
+    s.routeNames[name] = router
+    return nil
+}
+
+// 获取指定名称的路由对象，不存在时返回nil
+func (s *Server) GetRouteByName(name string) *Router {
+    s.rnmu.RLock()
+    defer s.rnmu.RUnlock()
+    return s.routeNames[name]
+}
+
+// 根据路由名称及路由参数生成URL地址(只包含URI及QueryString，不包含域名)。
+// 路由规则中的:name、{name}、*name参数会使用params中对应的键值进行替换，
+// 其中:name、{name}参数为必需参数，*name参数不存在时替换为空；
+// params中没有被路由规则使用的参数将会作为QueryString附加到URL末尾。
+func (s *Server) URLFor(name string, params...map[string]interface{}) (string, error) {
+    router := s.GetRouteByName(name)
+    if router == nil {
+        return "", errors.New(`route name "` + name + `" not found`)
+    }
+    values := make(map[string]interface{})
+    if len(params) > 0 {
+        for k, v := range params[0] {
+            values[k] = v
+        }
+    }
+    uri := ""
+    if router.Uri != "/" {
+        for _, v := range strings.Split(router.Uri[1:], "/") {
+            if len(v) == 0 {
+                continue
+            }
+            switch v[0] {
+                case ':':
+                    if len(v) == 1 {
+                        return "", errors.New(`route "` + router.Uri + `" contains anonymous parameter, cannot build url`)
+                    }
+                    value, ok := values[v[1:]]
+                    if !ok {
+                        return "", errors.New(`missing route parameter "` + v[1:] + `" for route "` + name + `"`)
+                    }
+                    uri += "/" + url.PathEscape(gconv.String(value))
+                    delete(values, v[1:])
+
+                case '*':
+                    if len(v) == 1 {
+                        continue
+                    }
+                    if value, ok := values[v[1:]]; ok {
+                        // 模糊匹配参数可以包含多级路径，因此按照层级分别编码
+                        for _, part := range strings.Split(strings.Trim(gconv.String(value), "/"), "/") {
+                            if len(part) > 0 {
+                                uri += "/" + url.PathEscape(part)
+                            }
+                        }
+                        delete(values, v[1:])
+                    }
+
+                default:
+                    var err error
+                    part, _ := gregex.ReplaceStringFunc(`\{[\w\.\-]+\}`, v, func(s string) string {
+                        key := s[1 : len(s) - 1]
+                        if value, ok := values[key]; ok {
+                            delete(values, key)
+                            return url.PathEscape(gconv.String(value))
+                        }
+                        err = errors.New(`missing route parameter "` + key + `" for route "` + name + `"`)
+                        return s
+                    })
+                    if err != nil {
+                        return "", err
+                    }
+                    uri += "/" + part
+            }
+        }
+    }
+    if uri == "" {
+        uri = "/"
+    }
+    // 剩余的参数作为QueryString(Encode会按照键名排序，保证生成的URL稳定)
+    if len(values) > 0 {
+        query := url.Values{}
+        for k, v := range values {
+            query.Add(k, gconv.String(v))
+        }
+        uri += "?" + query.Encode()
+    }
+    return uri, nil
+}
+
+// 根据路由名称及路由参数跳转到指定的路由地址
+func (r *Response) RedirectRoute(name string, params...map[string]interface{}) error {
+    location, err := r.Server.URLFor(name, params...)
+    if err != nil {
+        return err
+    }
+    r.RedirectTo(location)
+    return nil
+}
@@ -17,6 +17,16 @@ import (
 // 这种方式绑定的控制器每一次请求都会初始化一个新的控制器对象进行处理，对应不同的请求会话
 // 第三个参数methods用以指定需要注册的方法，支持多个方法名称，多个方法以英文“,”号分隔，区分大小写
 func (s *Server)BindController(pattern string, c Controller, methods...string) error {
+    return s.doBindController(pattern, c, "", methods...)
+}
+
+// 同BindController，并为注册的每个方法路由命名，路由名称格式为：name.方法名称，如：user.Show
+func (s *Server)BindControllerWithName(pattern string, c Controller, name string, methods...string) error {
+    return s.doBindController(pattern, c, name, methods...)
+}
+
+// 控制器注册，name为路由名称前缀(为空时不命名)
+func (s *Server)doBindController(pattern string, c Controller, name string, methods...string) error {
     methodMap := (map[string]bool)(nil)
     if len(methods) > 0 {
         methodMap = make(map[string]bool)
@@ -34,7 +44,7 @@ func (s *Server)BindController(pattern string, c Controller, methods...string) e
         if methodMap != nil && !methodMap[mname] {
             continue
         }
-        if mname == "Init" || mname == "Shut" || mname == "Exit"  {
+        if mname == "Init" || mname == "Shut" || mname == "Exit" || isApiDocMethod(c, mname) {
             continue
         }
         key   := s.mergeBuildInNameToPattern(pattern, sname, mname, true)
@@ -43,6 +53,8 @@ func (s *Server)BindController(pattern string, c Controller, methods...string) e
             ctype : v.Elem().Type(),
             fname : mname,
             faddr : nil,
+            rname : getMethodRouteName(name, mname),
+            doc   : getApiDoc(c, sname, mname),
         }
         // 如果方法中带有Index方法，那么额外自动增加一个路由规则匹配主URI
         if strings.EqualFold(mname, "Index") {
@@ -53,19 +65,24 @@ func (s *Server)BindController(pattern string, c Controller, methods...string) e
                     p = "/"
                 }
             }
+            // 主URI与方法URI相同时(如使用了{.method}规则)不需要重复注册，以免覆盖方法路由的名称
+            if p == key {
+                continue
+            }
             m[p] = &handlerItem {
                 rtype : gROUTE_REGISTER_CONTROLLER,
                 ctype : v.Elem().Type(),
                 fname : mname,
                 faddr : nil,
+                doc   : getApiDoc(c, sname, mname),
             }
         }
     }
     return s.bindHandlerByMap(m)
 }
 
-// 绑定路由到指定的方法执行
-func (s *Server)BindControllerMethod(pattern string, c Controller, method string) error {
+// 绑定路由到指定的方法执行，第四个参数name为可选的路由名称
+func (s *Server)BindControllerMethod(pattern string, c Controller, method string, name...string) error {
     m     := make(handlerMap)
     v     := reflect.ValueOf(c)
     e     := v.Type().Elem()
@@ -81,6 +98,8 @@ func (s *Server)BindControllerMethod(pattern string, c Controller, method string
         ctype : t,
         fname : mname,
         faddr : nil,
+        rname : getRouteName(name),
+        doc   : getApiDoc(c, sname, mname),
     }
     return s.bindHandlerByMap(m)
 }
@@ -89,24 +108,28 @@ func (s *Server)BindControllerMethod(pattern string, c Controller, method string
 // 方法会识别HTTP方法，并做REST绑定处理，例如：Post方法会绑定到HTTP POST的方法请求处理，Delete方法会绑定到HTTP DELETE的方法请求处理
 // 因此只会绑定HTTP Method对应的方法，其他方法不会自动注册绑定
 // 这种方式绑定的控制器每一次请求都会初始化一个新的控制器对象进行处理，对应不同的请求会话
-func (s *Server)BindControllerRest(pattern string, c Controller) error {
+// 第三个参数name为可选的路由名称，所有HTTP Method对应的路由共用该名称
+func (s *Server)BindControllerRest(pattern string, c Controller, name...string) error {
     // 遍历控制器，获取方法列表，并构造成uri
-    m := make(handlerMap)
-    v := reflect.ValueOf(c)
-    t := v.Type()
+    m     := make(handlerMap)
+    v     := reflect.ValueOf(c)
+    t     := v.Type()
+    rname := getRouteName(name)
     // 如果存在与HttpMethod对应名字的方法，那么绑定这些方法
     for i := 0; i < v.NumMethod(); i++ {
-        name   := t.Method(i).Name
-        method := strings.ToUpper(name)
+        mname  := t.Method(i).Name
+        method := strings.ToUpper(mname)
         if _, ok := s.methodsMap[method]; !ok {
             continue
         }
-        key   := name + ":" + pattern
+        key   := mname + ":" + pattern
         m[key] = &handlerItem {
             rtype : gROUTE_REGISTER_CONTROLLER,
             ctype : v.Elem().Type(),
-            fname : name,
+            fname : mname,
             faddr : nil,
+            rname : rname,
+            doc   : getApiDoc(c, t.Elem().Name(), mname),
         }
     }
     return s.bindHandlerByMap(m)
@@ -15,12 +15,14 @@ import (
 )
 
 // 注意该方法是直接绑定函数的内存地址，执行的时候直接执行该方法，不会存在初始化新的控制器逻辑
-func (s *Server) BindHandler(pattern string, handler HandlerFunc) error {
+// 第三个参数name为可选的路由名称，可通过URLFor方法根据路由名称反向生成URL
+func (s *Server) BindHandler(pattern string, handler HandlerFunc, name...string) error {
     return s.bindHandlerItem(pattern, &handlerItem {
         rtype : gROUTE_REGISTER_HANDLER,
         ctype : nil,
         fname : "",
         faddr : handler,
+        rname : getRouteName(name),
     })
 }
 
@@ -44,6 +46,22 @@ func (s *Server) bindHandlerByMap(m handlerMap) error {
     return nil
 }
 
+// 获取可选的路由名称参数
+func getRouteName(name []string) string {
+    if len(name) > 0 {
+        return strings.TrimSpace(name[0])
+    }
+    return ""
+}
+
+// 批量注册对象/控制器方法时的路由名称，格式为：name.方法名称，name为空时不命名
+func getMethodRouteName(name string, method string) string {
+    if name = strings.TrimSpace(name); name == "" {
+        return ""
+    }
+    return name + "." + method
+}
+
 // 将内置的名称按照设定的规则合并到pattern中，内置名称按照{.xxx}规则命名。
 // 规则1：pattern中的URI包含{.struct}关键字，则替换该关键字为结构体名称；
 // 规则1：pattern中的URI包含{.method}关键字，则替换该关键字为方法名称；
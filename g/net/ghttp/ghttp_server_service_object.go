@@ -16,6 +16,16 @@ import (
 // 绑定对象到URI请求处理中，会自动识别方法名称，并附加到对应的URI地址后面
 // 第三个参数methods用以指定需要注册的方法，支持多个方法名称，多个方法以英文“,”号分隔，区分大小写
 func (s *Server)BindObject(pattern string, obj interface{}, methods...string) error {
+    return s.doBindObject(pattern, obj, "", methods...)
+}
+
+// 同BindObject，并为注册的每个方法路由命名，路由名称格式为：name.方法名称，如：user.Show
+func (s *Server)BindObjectWithName(pattern string, obj interface{}, name string, methods...string) error {
+    return s.doBindObject(pattern, obj, name, methods...)
+}
+
+// 对象注册，name为路由名称前缀(为空时不命名)
+func (s *Server)doBindObject(pattern string, obj interface{}, name string, methods...string) error {
     methodMap := (map[string]bool)(nil)
     if len(methods) > 0 {
         methodMap = make(map[string]bool)
@@ -40,7 +50,7 @@ func (s *Server)BindObject(pattern string, obj interface{}, methods...string) er
         if methodMap != nil && !methodMap[mname] {
             continue
         }
-        if mname == "Init" || mname == "Shut" {
+        if mname == "Init" || mname == "Shut" || isApiDocMethod(obj, mname) {
             continue
         }
         key    := s.mergeBuildInNameToPattern(pattern, sname, mname, true)
@@ -51,6 +61,8 @@ func (s *Server)BindObject(pattern string, obj interface{}, methods...string) er
             faddr : v.Method(i).Interface().(func(*Request)),
             finit : finit,
             fshut : fshut,
+            rname : getMethodRouteName(name, mname),
+            doc   : getApiDoc(obj, sname, mname),
         }
         // 如果方法中带有Index方法，那么额外自动增加一个路由规则匹配主URI
         if strings.EqualFold(mname, "Index") {
@@ -61,6 +73,10 @@ func (s *Server)BindObject(pattern string, obj interface{}, methods...string) er
                     p = "/"
                 }
             }
+            // 主URI与方法URI相同时(如使用了{.method}规则)不需要重复注册，以免覆盖方法路由的名称
+            if p == key {
+                continue
+            }
             m[p] = &handlerItem {
                 rtype : gROUTE_REGISTER_OBJECT,
                 ctype : nil,
@@ -68,6 +84,7 @@ func (s *Server)BindObject(pattern string, obj interface{}, methods...string) er
                 faddr : v.Method(i).Interface().(func(*Request)),
                 finit : finit,
                 fshut : fshut,
+                doc   : getApiDoc(obj, sname, mname),
             }
         }
     }
@@ -76,7 +93,8 @@ func (s *Server)BindObject(pattern string, obj interface{}, methods...string) er
 
 // 绑定对象到URI请求处理中，会自动识别方法名称，并附加到对应的URI地址后面
 // 第三个参数methods支持多个方法注册，多个方法以英文“,”号分隔，区分大小写
-func (s *Server)BindObjectMethod(pattern string, obj interface{}, method string) error {
+// 第四个参数name为可选的路由名称
+func (s *Server)BindObjectMethod(pattern string, obj interface{}, method string, name...string) error {
     m     := make(handlerMap)
     v     := reflect.ValueOf(obj)
     t     := v.Type()
@@ -102,6 +120,8 @@ func (s *Server)BindObjectMethod(pattern string, obj interface{}, method string)
         faddr : fval.Interface().(func(*Request)),
         finit : finit,
         fshut : fshut,
+        rname : getRouteName(name),
+        doc   : getApiDoc(obj, sname, mname),
     }
 
     return s.bindHandlerByMap(m)
@@ -109,7 +129,8 @@ func (s *Server)BindObjectMethod(pattern string, obj interface{}, method string)
 
 // 绑定对象到URI请求处理中，会自动识别方法名称，并附加到对应的URI地址后面
 // 需要注意对象方法的定义必须按照ghttp.HandlerFunc来定义
-func (s *Server)BindObjectRest(pattern string, obj interface{}) error {
+// 第三个参数name为可选的路由名称，所有HTTP Method对应的路由共用该名称
+func (s *Server)BindObjectRest(pattern string, obj interface{}, name...string) error {
     m     := make(handlerMap)
     v     := reflect.ValueOf(obj)
     t     := v.Type()
@@ -121,13 +142,14 @@ func (s *Server)BindObjectRest(pattern string, obj interface{}) error {
     if v.MethodByName("Shut").IsValid() {
         fshut = v.MethodByName("Shut").Interface().(func(*Request))
     }
+    rname := getRouteName(name)
     for i := 0; i < v.NumMethod(); i++ {
-        name   := t.Method(i).Name
-        method := strings.ToUpper(name)
+        mname  := t.Method(i).Name
+        method := strings.ToUpper(mname)
         if _, ok := s.methodsMap[method]; !ok {
             continue
         }
-        key   := name + ":" + pattern
+        key   := mname + ":" + pattern
         m[key] = &handlerItem {
             rtype : gROUTE_REGISTER_OBJECT,
             ctype : nil,
@@ -135,6 +157,8 @@ func (s *Server)BindObjectRest(pattern string, obj interface{}) error {
             faddr : v.Method(i).Interface().(func(*Request)),
             finit : finit,
             fshut : fshut,
+            rname : rname,
+            doc   : getApiDoc(obj, t.Elem().Name(), mname),
         }
     }
     return s.bindHandlerByMap(m)
@@ -11,18 +11,27 @@ import (
     "sync"
     "strconv"
     "strings"
+    "gitee.com/johng/gf/g/os/glog"
     "gitee.com/johng/gf/g/os/gtime"
     "gitee.com/johng/gf/g/util/grand"
     "gitee.com/johng/gf/g/util/gconv"
     "gitee.com/johng/gf/g/container/gmap"
 )
 
-// 单个session对象
+// 单个session对象，
+// Session数据在第一次使用时才从存储中加载(懒加载)，并在请求结束时写回存储。
+// 写回时只合并当前请求变更过的键名：重新读取存储中的最新数据，再写入变更的键值(或删除被删除的键名)，
+// 因此同一Session的并发请求修改不同的键名时不会互相覆盖；修改同一键名时以最后写回的请求为准。
+// 注意读取与写入之间没有加锁(存储接口不提供锁机制)，并发请求在极短时间内同时写回时仍然可能丢失变更。
 type Session struct {
-    mu     sync.RWMutex             // 并发安全互斥锁
-    id     string                   // SessionId
-    data   *gmap.StringInterfaceMap // Session数据
-    server *Server                  // 所属Server
+    mu      sync.RWMutex             // 并发安全互斥锁
+    id      string                   // SessionId
+    data    *gmap.StringInterfaceMap // Session数据
+    dirty   map[string]bool          // 当前请求变更过的键名(true:设置，false:删除)
+    cleared bool                     // 当前请求是否清空过Session数据
+    closed  bool                     // 是否已经写回存储(请求结束时只处理一次)
+    server  *Server                  // 所属Server
+    request *Request                 // 所属请求对象
 }
 
 // 生成一个唯一的sessionid字符串
@@ -30,35 +39,68 @@ func makeSessionId() string {
     return strings.ToUpper(strconv.FormatInt(gtime.Nanosecond(), 32) + grand.RandStr(3))
 }
 
-// 获取或者生成一个session对象
+// 获取或者生成一个session对象，注意此时并不会加载session数据
 func GetSession(r *Request) *Session {
-    s   := r.Server
-    sid := r.Cookie.SessionId()
-    if r := s.sessions.Get(sid); r != nil {
-        return r.(*Session)
+    if r.Session != nil {
+        return r.Session
     }
-    ses := &Session {
-        id     : sid,
-        data   : gmap.NewStringInterfaceMap(),
-        server : s,
+    return &Session {
+        server  : r.Server,
+        request : r,
     }
-    s.sessions.Set(sid, ses, s.GetSessionMaxAge())
-    return ses
+}
+
+// 初始化session数据，从存储中加载数据(只会加载一次)
+func (s *Session) init() {
+    s.mu.RLock()
+    inited := s.data != nil
+    s.mu.RUnlock()
+    if inited {
+        return
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.data != nil {
+        return
+    }
+    s.id    = s.request.Cookie.SessionId()
+    s.data  = gmap.NewStringInterfaceMap()
+    s.dirty = make(map[string]bool)
+    data, err := s.server.sessionStorage.Get(s.id)
+    if err != nil {
+        glog.Error(err)
+    }
+    if data != nil {
+        s.data.BatchSet(data)
+    }
+}
+
+// 标记session键名已变更，set为false表示键名被删除
+func (s *Session) setDirty(set bool, keys...string) {
+    s.mu.Lock()
+    for _, k := range keys {
+        s.dirty[k] = set
+    }
+    s.mu.Unlock()
 }
 
 // 获取sessionid
 func (s *Session) Id() string {
+    s.init()
     return s.id
 }
 
 // 获取当前session所有数据
 func (s *Session) Data () map[string]interface{} {
+    s.init()
     return s.data.Clone()
 }
 
 // 设置session
 func (s *Session) Set (k string, v interface{}) {
+    s.init()
     s.data.Set(k, v)
+    s.setDirty(true, k)
 }
 
 // 批量设置(BatchSet别名)
@@ -68,16 +110,24 @@ func (s *Session) Sets (m map[string]interface{}) {
 
 // 批量设置
 func (s *Session) BatchSet (m map[string]interface{}) {
+    s.init()
     s.data.BatchSet(m)
+    keys := make([]string, 0, len(m))
+    for k, _ := range m {
+        keys = append(keys, k)
+    }
+    s.setDirty(true, keys...)
 }
 
 // 判断键名是否存在
 func (s *Session) Contains (k string) bool {
+    s.init()
     return s.data.Get(k) != nil
 }
 
 // 获取session
 func (s *Session) Get (k string) interface{} {
+    s.init()
     return s.data.Get(k)
 }
 
@@ -111,10 +161,72 @@ func (s *Session) GetFloat64 (k string) float64 {
 
 // 删除session
 func (s *Session) Remove (k string) {
+    s.init()
     s.data.Remove(k)
+    s.setDirty(false, k)
+}
+
+// 清空session数据，并从存储中删除
+func (s *Session) Clear() {
+    s.init()
+    s.data.Clear()
+    s.mu.Lock()
+    s.dirty   = make(map[string]bool)
+    s.cleared = true
+    s.mu.Unlock()
+    if err := s.server.sessionStorage.Remove(s.id); err != nil {
+        glog.Error(err)
+    }
 }
 
 // 更新过期时间(如果用在守护进程中长期使用，需要手动调用进行更新，防止超时被清除)
 func (s *Session) UpdateExpire() {
-    s.server.sessions.Set(s.id, s, s.server.sessionMaxAge.Val()*1000)
-}
\ No newline at end of file
+    s.init()
+    if err := s.server.sessionStorage.UpdateTTL(s.id, s.server.GetSessionMaxAge()); err != nil {
+        glog.Error(err)
+    }
+}
+
+// 请求结束时将session数据写回存储，如果数据没有变更那么只更新过期时间，
+// 如果当前请求没有使用session，那么不做任何处理；
+// 该方法在请求正常结束或者处理过程中产生panic时都会被调用，但只会执行一次。
+func (s *Session) close() {
+    s.mu.Lock()
+    if s.closed || s.data == nil {
+        s.closed = true
+        s.mu.Unlock()
+        return
+    }
+    s.closed = true
+    dirty   := s.dirty
+    cleared := s.cleared
+    s.dirty  = make(map[string]bool)
+    s.mu.Unlock()
+    if len(dirty) == 0 {
+        if !cleared {
+            s.UpdateExpire()
+        }
+        return
+    }
+    // 合并变更的键名到存储中的最新数据，避免覆盖同一Session其他并发请求的修改
+    data := make(map[string]interface{})
+    if !cleared {
+        latest, err := s.server.sessionStorage.Get(s.id)
+        if err != nil {
+            glog.Error(err)
+        }
+        for k, v := range latest {
+            data[k] = v
+        }
+    }
+    for k, set := range dirty {
+        if set {
+            data[k] = s.data.Get(k)
+        } else {
+            delete(data, k)
+        }
+    }
+    if err := s.server.sessionStorage.Set(s.id, data, s.server.GetSessionMaxAge()); err != nil {
+        glog.Error(err)
+    }
+}
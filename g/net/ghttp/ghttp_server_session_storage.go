@@ -0,0 +1,70 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// Session存储接口及默认的内存存储实现.
+
+package ghttp
+
+import (
+    "gitee.com/johng/gf/g/os/gcache"
+)
+
+// Session存储接口，通过ServerConfig.SessionStorage或者Server.SetSessionStorage设置。
+// 内置了内存(默认)、文件及Redis三种存储实现，开发者也可以自定义实现该接口。
+type SessionStorage interface {
+    // 获取指定SessionId的数据，当数据不存在或者已过期时返回nil
+    Get(id string) (map[string]interface{}, error)
+    // 保存指定SessionId的数据，maxAge为有效期(秒)
+    Set(id string, data map[string]interface{}, maxAge int) error
+    // 更新指定SessionId的有效期(秒)
+    UpdateTTL(id string, maxAge int) error
+    // 删除指定SessionId的数据
+    Remove(id string) error
+}
+
+// 可选的Session存储关闭接口，存储对象实现该接口时，Server关闭时会调用Close方法释放资源(如停止定时清理)
+type SessionStorageCloser interface {
+    Close() error
+}
+
+// 内存Session存储，进程重启后数据会丢失，并且无法在多个进程之间共享
+type SessionStorageMemory struct {
+    cache *gcache.Cache
+}
+
+// 创建内存Session存储对象
+func NewSessionStorageMemory() *SessionStorageMemory {
+    return &SessionStorageMemory {
+        cache : gcache.New(),
+    }
+}
+
+// 获取Session数据
+func (s *SessionStorageMemory) Get(id string) (map[string]interface{}, error) {
+    if v := s.cache.Get(id); v != nil {
+        return v.(map[string]interface{}), nil
+    }
+    return nil, nil
+}
+
+// 保存Session数据
+func (s *SessionStorageMemory) Set(id string, data map[string]interface{}, maxAge int) error {
+    s.cache.Set(id, data, maxAge*1000)
+    return nil
+}
+
+// 更新Session有效期
+func (s *SessionStorageMemory) UpdateTTL(id string, maxAge int) error {
+    if v := s.cache.Get(id); v != nil {
+        s.cache.Set(id, v, maxAge*1000)
+    }
+    return nil
+}
+
+// 删除Session数据
+func (s *SessionStorageMemory) Remove(id string) error {
+    s.cache.Remove(id)
+    return nil
+}
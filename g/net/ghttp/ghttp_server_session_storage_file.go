@@ -0,0 +1,144 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// Session文件存储.
+
+package ghttp
+
+import (
+    "os"
+    "time"
+    "strings"
+    "gitee.com/johng/gf/g/os/gfile"
+    "gitee.com/johng/gf/g/os/gtime"
+    "gitee.com/johng/gf/g/encoding/gjson"
+    "gitee.com/johng/gf/g/container/gtype"
+)
+
+const (
+    gSESSION_FILE_CLEAR_INTERVAL = time.Minute // 过期Session文件的清理间隔
+    gSESSION_FILE_EXT            = ".session"  // Session文件后缀
+)
+
+// 文件Session存储，每个Session对应目录下的一个文件，文件内容为json格式的Session数据，
+// 文件的修改时间加上有效期即为Session的过期时间，进程重启后Session数据不会丢失。
+type SessionStorageFile struct {
+    path   string
+    closed *gtype.Bool // 是否已关闭(关闭后停止定时清理)
+}
+
+// 创建文件Session存储对象，path为存放Session文件的目录，不传递时默认使用系统临时目录下的gsessions目录
+func NewSessionStorageFile(path...string) *SessionStorageFile {
+    p := gfile.TempDir() + gfile.Separator + "gsessions"
+    if len(path) > 0 && path[0] != "" {
+        p = strings.TrimRight(path[0], gfile.Separator)
+    }
+    if !gfile.Exists(p) {
+        if err := gfile.Mkdir(p); err != nil {
+            panic(err)
+        }
+    }
+    s := &SessionStorageFile {
+        path   : p,
+        closed : gtype.NewBool(),
+    }
+    // 定时清理过期的Session文件，存储对象关闭后停止
+    gtime.SetInterval(gSESSION_FILE_CLEAR_INTERVAL, func() bool {
+        if s.closed.Val() {
+            return false
+        }
+        s.clearExpired()
+        return true
+    })
+    return s
+}
+
+// 关闭存储对象，停止过期Session文件的定时清理(Server关闭时自动调用)
+func (s *SessionStorageFile) Close() error {
+    s.closed.Set(true)
+    return nil
+}
+
+// 获取Session数据存放的文件路径，SessionId中的特殊字符会被过滤，防止路径穿越
+func (s *SessionStorageFile) filePath(id string) string {
+    id = strings.Map(func(r rune) rune {
+        if (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '-' || r == '_' {
+            return r
+        }
+        return -1
+    }, id)
+    return s.path + gfile.Separator + id + gSESSION_FILE_EXT
+}
+
+// 获取Session数据
+func (s *SessionStorageFile) Get(id string) (map[string]interface{}, error) {
+    path    := s.filePath(id)
+    content := gfile.GetBinContents(path)
+    if len(content) == 0 {
+        return nil, nil
+    }
+    j, err := gjson.DecodeToJson(content)
+    if err != nil {
+        return nil, err
+    }
+    // 文件的修改时间即为最后一次保存的时间
+    if gtime.Second() > gfile.MTime(path) + j.GetInt64("maxAge") {
+        gfile.Remove(path)
+        return nil, nil
+    }
+    return j.GetMap("data"), nil
+}
+
+// 保存Session数据
+func (s *SessionStorageFile) Set(id string, data map[string]interface{}, maxAge int) error {
+    content, err := gjson.Encode(map[string]interface{} {
+        "maxAge" : maxAge,
+        "data"   : data,
+    })
+    if err != nil {
+        return err
+    }
+    return gfile.PutBinContents(s.filePath(id), content)
+}
+
+// 更新Session有效期，如果有效期没有变化那么只需要更新文件的修改时间
+func (s *SessionStorageFile) UpdateTTL(id string, maxAge int) error {
+    path    := s.filePath(id)
+    content := gfile.GetBinContents(path)
+    if len(content) == 0 {
+        return nil
+    }
+    j, err := gjson.DecodeToJson(content)
+    if err != nil {
+        return err
+    }
+    if j.GetInt("maxAge") != maxAge {
+        return s.Set(id, j.GetMap("data"), maxAge)
+    }
+    now := time.Now()
+    return os.Chtimes(path, now, now)
+}
+
+// 删除Session数据
+func (s *SessionStorageFile) Remove(id string) error {
+    path := s.filePath(id)
+    if gfile.Exists(path) {
+        return gfile.Remove(path)
+    }
+    return nil
+}
+
+// 清理过期的Session文件
+func (s *SessionStorageFile) clearExpired() {
+    files, _ := gfile.Glob(s.path + gfile.Separator + "*" + gSESSION_FILE_EXT)
+    for _, path := range files {
+        if j, err := gjson.DecodeToJson(gfile.GetBinContents(path)); err == nil {
+            if gtime.Second() <= gfile.MTime(path) + j.GetInt64("maxAge") {
+                continue
+            }
+        }
+        gfile.Remove(path)
+    }
+}
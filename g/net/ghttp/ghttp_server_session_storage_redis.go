@@ -0,0 +1,79 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// Session Redis存储.
+
+package ghttp
+
+import (
+    "gitee.com/johng/gf/g/util/gconv"
+    "gitee.com/johng/gf/g/encoding/gjson"
+    "gitee.com/johng/gf/g/database/gredis"
+)
+
+const (
+    gSESSION_REDIS_KEY_PREFIX = "gf_session_" // Redis中Session键名的默认前缀
+)
+
+// Redis Session存储，Session数据以json格式存放在Redis中，并使用Redis的过期机制管理有效期，
+// 多个服务实例使用同一个Redis即可共享Session。
+type SessionStorageRedis struct {
+    config gredis.Config // Redis配置(每次操作时从连接池获取连接)
+    prefix string        // 键名前缀
+}
+
+// 创建Redis Session存储对象，prefix为Redis中Session键名的前缀，不传递时使用默认前缀
+func NewSessionStorageRedis(config gredis.Config, prefix...string) *SessionStorageRedis {
+    s := &SessionStorageRedis {
+        config : config,
+        prefix : gSESSION_REDIS_KEY_PREFIX,
+    }
+    if len(prefix) > 0 {
+        s.prefix = prefix[0]
+    }
+    return s
+}
+
+// 执行Redis命令，执行完毕后将连接放回连接池
+func (s *SessionStorageRedis) do(command string, args ...interface{}) (interface{}, error) {
+    redis := gredis.New(s.config)
+    defer redis.Close()
+    return redis.Do(command, args...)
+}
+
+// 获取Session数据
+func (s *SessionStorageRedis) Get(id string) (map[string]interface{}, error) {
+    r, err := s.do("GET", s.prefix + id)
+    if err != nil || r == nil {
+        return nil, err
+    }
+    j, err := gjson.DecodeToJson(gconv.Bytes(r))
+    if err != nil {
+        return nil, err
+    }
+    return j.ToMap(), nil
+}
+
+// 保存Session数据
+func (s *SessionStorageRedis) Set(id string, data map[string]interface{}, maxAge int) error {
+    content, err := gjson.Encode(data)
+    if err != nil {
+        return err
+    }
+    _, err = s.do("SETEX", s.prefix + id, maxAge, content)
+    return err
+}
+
+// 更新Session有效期
+func (s *SessionStorageRedis) UpdateTTL(id string, maxAge int) error {
+    _, err := s.do("EXPIRE", s.prefix + id, maxAge)
+    return err
+}
+
+// 删除Session数据
+func (s *SessionStorageRedis) Remove(id string) error {
+    _, err := s.do("DEL", s.prefix + id)
+    return err
+}
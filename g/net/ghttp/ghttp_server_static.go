@@ -0,0 +1,254 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 静态文件服务(缓存控制、预压缩文件、断点续传及自定义文件系统).
+
+package ghttp
+
+import (
+    "os"
+    "fmt"
+    "mime"
+    "time"
+    "strings"
+    "net/http"
+    "path/filepath"
+    "gitee.com/johng/gf/g/os/gfile"
+)
+
+// 静态文件缓存配置
+type StaticCacheConfig struct {
+    CacheControl        string // 输出的Cache-Control头，为空表示不输出，如：public, max-age=86400
+    DisableETag         bool   // 是否禁止输出ETag(默认根据文件修改时间及大小生成)
+    DisableLastModified bool   // 是否禁止输出Last-Modified
+}
+
+// 静态文件系统项
+type staticFSItem struct {
+    prefix string          // URI前缀
+    fs     http.FileSystem // 文件系统
+}
+
+// 检索到的静态文件
+type staticFile struct {
+    fs   http.FileSystem // 文件所在的文件系统
+    path string          // 文件在文件系统中的路径
+    dir  bool            // 是否为目录
+}
+
+// 本地文件系统(path为本地绝对路径)
+type osFileSystem struct {}
+
+// 直接输出到客户端的ResponseWriter(不经过缓冲区)，用于大文件及断点续传
+type staticFileWriter struct {
+    response *Response
+}
+
+// 预压缩文件后缀，按照优先级排序
+var staticPrecompressedExts = []struct {
+    encoding string
+    ext      string
+} {
+    {"br",   ".br"},
+    {"gzip", ".gz"},
+}
+
+// 实现http.FileSystem接口
+func (fs osFileSystem) Open(name string) (http.File, error) {
+    return os.Open(name)
+}
+
+func (w *staticFileWriter) Header() http.Header {
+    return w.response.Header()
+}
+
+func (w *staticFileWriter) Write(buffer []byte) (int, error) {
+    n, err := w.response.Writer.ResponseWriter.Write(buffer)
+    w.response.length += n
+    return n, err
+}
+
+func (w *staticFileWriter) WriteHeader(code int) {
+    w.response.Writer.WriteHeader(code)
+}
+
+// 根据URI检索静态文件，优先检索本地目录，其次按照添加顺序检索静态文件系统，
+// 检索到目录时会尝试检索目录下的index文件，不存在index文件时返回目录本身。
+func (s *Server) searchStaticFile(uri string) *staticFile {
+    if path := s.paths.Search(uri); path != "" {
+        if !gfile.IsDir(path) {
+            return &staticFile{fs : osFileSystem{}, path : path}
+        }
+        for _, file := range s.config.IndexFiles {
+            if fpath := s.paths.Search(path + gfile.Separator + file); fpath != "" {
+                return &staticFile{fs : osFileSystem{}, path : fpath}
+            }
+        }
+        return &staticFile{fs : osFileSystem{}, path : path, dir : true}
+    }
+    for _, item := range s.staticFS {
+        name := ""
+        if item.prefix == "/" {
+            name = uri
+        } else if uri == item.prefix || strings.HasPrefix(uri, item.prefix + "/") {
+            name = uri[len(item.prefix):]
+        } else {
+            continue
+        }
+        if name == "" {
+            name = "/"
+        }
+        isDir, ok := statStaticFile(item.fs, name)
+        if !ok {
+            continue
+        }
+        if !isDir {
+            return &staticFile{fs : item.fs, path : name}
+        }
+        for _, file := range s.config.IndexFiles {
+            fpath := strings.TrimRight(name, "/") + "/" + file
+            if isDir, ok := statStaticFile(item.fs, fpath); ok && !isDir {
+                return &staticFile{fs : item.fs, path : fpath}
+            }
+        }
+        return &staticFile{fs : item.fs, path : name, dir : true}
+    }
+    return nil
+}
+
+// 判断文件系统中的文件是否存在，以及是否为目录
+func statStaticFile(fs http.FileSystem, name string) (isDir bool, ok bool) {
+    f, err := fs.Open(name)
+    if err != nil {
+        return false, false
+    }
+    defer f.Close()
+    info, err := f.Stat()
+    if err != nil {
+        return false, false
+    }
+    return info.IsDir(), true
+}
+
+// 输出静态文件，支持缓存控制(Cache-Control/ETag/Last-Modified)、预压缩文件选择及Range请求。
+// 文件内容不经过缓冲区，直接输出到客户端。
+func (s *Server) serveStaticFile(r *Request, file *staticFile) {
+    f, err := file.fs.Open(file.path)
+    if err != nil {
+        if os.IsNotExist(err) {
+            r.Response.WriteStatus(http.StatusNotFound)
+        } else {
+            r.Response.WriteStatus(http.StatusForbidden)
+        }
+        return
+    }
+    defer f.Close()
+    info, err := f.Stat()
+    if err != nil {
+        r.Response.WriteStatus(http.StatusForbidden)
+        return
+    }
+    if info.IsDir() {
+        if s.config.IndexFolder {
+            s.listDir(r, f)
+        } else {
+            r.Response.WriteStatus(http.StatusForbidden)
+        }
+        return
+    }
+    header   := r.Response.Header()
+    name     := info.Name()
+    content  := f
+    encoding := ""
+    // 预压缩文件
+    if s.config.StaticPrecompressed {
+        if ctype := mime.TypeByExtension(filepath.Ext(name)); ctype != "" {
+            accepts := parseAcceptEncoding(r.Header.Get("Accept-Encoding"))
+            for _, item := range staticPrecompressedExts {
+                if _, ok := accepts[item.encoding]; !ok {
+                    continue
+                }
+                cf, err := file.fs.Open(file.path + item.ext)
+                if err != nil {
+                    continue
+                }
+                cinfo, err := cf.Stat()
+                if err != nil || cinfo.IsDir() {
+                    cf.Close()
+                    continue
+                }
+                defer cf.Close()
+                content, info, encoding = cf, cinfo, item.encoding
+                header.Set("Content-Encoding", encoding)
+                header.Set("Content-Type",     ctype)
+                break
+            }
+            // 存在预压缩文件时，不同的Accept-Encoding返回的内容不同
+            header.Add("Vary", "Accept-Encoding")
+        }
+    }
+    // 缓存控制
+    config, _ := s.getStaticCacheConfig(r.URL.Path)
+    if config.CacheControl != "" {
+        header.Set("Cache-Control", config.CacheControl)
+    }
+    if !config.DisableETag {
+        // 资源包中的文件可能没有修改时间，此时只使用文件大小
+        etag := fmt.Sprintf(`"%x`, info.Size())
+        if !info.ModTime().IsZero() {
+            etag = fmt.Sprintf(`"%x-%x`, info.ModTime().UnixNano(), info.Size())
+        }
+        if encoding != "" {
+            etag += "-" + encoding
+        }
+        header.Set("ETag", etag + `"`)
+    }
+    modtime := info.ModTime()
+    if config.DisableLastModified {
+        modtime = time.Time{}
+    }
+    header.Set("Server", s.config.ServerAgent)
+    http.ServeContent(&staticFileWriter{r.Response}, &r.Request, name, modtime, content)
+}
+
+// 按照URI最长前缀匹配静态文件缓存配置
+func (s *Server) getStaticCacheConfig(uri string) (StaticCacheConfig, bool) {
+    match  := ""
+    found  := false
+    config := StaticCacheConfig{}
+    for prefix, v := range s.config.StaticCaches {
+        p := "/" + strings.Trim(prefix, "/")
+        if p != "/" && uri != p && !strings.HasPrefix(uri, p + "/") {
+            continue
+        }
+        if !found || len(p) > len(match) {
+            match, config, found = p, v, true
+        }
+    }
+    return config, found
+}
+
+// 解析Accept-Encoding，返回客户端可接受的编码(q=0表示不接受)
+func parseAcceptEncoding(value string) map[string]struct{} {
+    accepts := make(map[string]struct{})
+    for _, item := range strings.Split(value, ",") {
+        parts := strings.Split(item, ";")
+        name  := strings.ToLower(strings.TrimSpace(parts[0]))
+        if name == "" {
+            continue
+        }
+        rejected := false
+        for _, param := range parts[1:] {
+            param = strings.Replace(strings.TrimSpace(param), " ", "", -1)
+            if strings.HasPrefix(param, "q=") && strings.Trim(param[2:], "0.") == "" {
+                rejected = true
+            }
+        }
+        if !rejected {
+            accepts[name] = struct{}{}
+        }
+    }
+    return accepts
+}
@@ -0,0 +1,155 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// HTTPS证书管理(SNI多证书及证书文件热更新).
+
+package ghttp
+
+import (
+    "sync"
+    "errors"
+    "strings"
+    "crypto/tls"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/os/gfsnotify"
+)
+
+// 证书项
+type tlsCertItem struct {
+    certFile string           // 证书文件路径
+    keyFile  string           // 私钥文件路径
+    cert     *tls.Certificate // 已加载的证书
+}
+
+// 证书管理对象
+type serverCertificates struct {
+    mu      sync.RWMutex            // 证书读写锁
+    def     *tlsCertItem            // 默认证书(Server.EnableHTTPS设置)
+    domains map[string]*tlsCertItem // 域名证书(Domain.EnableHTTPS设置)，用于SNI，键名为小写域名，支持*.example.com形式的泛域名
+}
+
+// 创建证书管理对象
+func newServerCertificates() *serverCertificates {
+    return &serverCertificates {
+        domains : make(map[string]*tlsCertItem),
+    }
+}
+
+// 为域名开启HTTPS支持，客户端通过SNI访问该域名时使用给定的证书(证书文件变更时自动重新加载)，
+// 没有匹配到域名证书时使用Server.EnableHTTPS设置的默认证书
+func (d *Domain) EnableHTTPS(certFile, keyFile string) error {
+    item, err := d.s.certificates.newItem(certFile, keyFile)
+    if err != nil {
+        return err
+    }
+    d.s.certificates.mu.Lock()
+    for domain, _ := range d.m {
+        d.s.certificates.domains[strings.ToLower(domain)] = item
+    }
+    d.s.certificates.mu.Unlock()
+    return nil
+}
+
+// 设置默认证书(同一证书文件重复设置时不会重复加载)
+func (c *serverCertificates) setDefault(certFile, keyFile string) error {
+    c.mu.RLock()
+    def := c.def
+    c.mu.RUnlock()
+    if def != nil && def.certFile == certFile && def.keyFile == keyFile {
+        return nil
+    }
+    item, err := c.newItem(certFile, keyFile)
+    if err != nil {
+        return err
+    }
+    c.mu.Lock()
+    c.def = item
+    c.mu.Unlock()
+    return nil
+}
+
+// 是否设置了证书
+func (c *serverCertificates) isEmpty() bool {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    return c.def == nil && len(c.domains) == 0
+}
+
+// 加载证书，并监控证书文件变化，变化时自动重新加载
+func (c *serverCertificates) newItem(certFile, keyFile string) (*tlsCertItem, error) {
+    item := &tlsCertItem {
+        certFile : certFile,
+        keyFile  : keyFile,
+    }
+    if err := c.load(item); err != nil {
+        return nil, err
+    }
+    callback := func(event *gfsnotify.Event) {
+        // 证书及私钥文件通常会先后更新，不匹配时加载失败，继续使用原有证书，等待另一个文件更新后再次加载
+        if err := c.load(item); err != nil {
+            glog.Errorfln(`reload certificate "%s" failed: %v`, item.certFile, err)
+        } else {
+            glog.Printfln(`certificate "%s" reloaded`, item.certFile)
+        }
+    }
+    for _, path := range []string{certFile, keyFile} {
+        if err := gfsnotify.Add(path, callback); err != nil {
+            glog.Errorfln(`watch certificate file "%s" failed: %v`, path, err)
+        }
+    }
+    return item, nil
+}
+
+// 从文件加载证书
+func (c *serverCertificates) load(item *tlsCertItem) error {
+    cert, err := tls.LoadX509KeyPair(item.certFile, item.keyFile)
+    if err != nil {
+        return err
+    }
+    c.mu.Lock()
+    item.cert = &cert
+    c.mu.Unlock()
+    return nil
+}
+
+// 根据客户端SNI选择证书，用于tls.Config.GetCertificate，每次握手时获取，因此证书更新后对新连接立即生效
+func (c *serverCertificates) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    name := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+    if name != "" {
+        if item, ok := c.domains[name]; ok {
+            return item.cert, nil
+        }
+        if pos := strings.IndexByte(name, '.'); pos > 0 {
+            if item, ok := c.domains["*" + name[pos:]]; ok {
+                return item.cert, nil
+            }
+        }
+    }
+    if c.def != nil {
+        return c.def.cert, nil
+    }
+    return nil, errors.New(`no certificate for server name "` + hello.ServerName + `"`)
+}
+
+// 生成HTTPS服务使用的TLS配置
+func (s *Server) getTLSConfig() *tls.Config {
+    config := &tls.Config{}
+    if s.config.TLSConfig != nil {
+        config = s.config.TLSConfig.Clone()
+    }
+    if config.NextProtos == nil {
+        if s.config.HTTP2Enabled {
+            config.NextProtos = []string{"h2", "http/1.1"}
+        } else {
+            config.NextProtos = []string{"http/1.1"}
+        }
+    }
+    if len(config.Certificates) == 0 && config.GetCertificate == nil {
+        config.GetCertificate = s.certificates.getCertificate
+    }
+    return config
+}
@@ -0,0 +1,473 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// WebSocket连接管理(房间、广播、发送队列、心跳及跨实例广播).
+
+package ghttp
+
+import (
+    "sort"
+    "sync"
+    "time"
+    "errors"
+    "encoding/json"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/database/gredis"
+    "github.com/gorilla/websocket"
+)
+
+const (
+    gDEFAULT_WS_SEND_QUEUE_SIZE = 256              // 默认的连接发送队列长度
+    gDEFAULT_WS_WRITE_TIMEOUT   = 10 * time.Second // 默认的消息写超时时间
+    gDEFAULT_WS_PING_INTERVAL   = 30 * time.Second // 默认的ping间隔
+    gDEFAULT_WS_REDIS_CHANNEL   = "gf:ws:hub"      // 默认的跨实例广播redis频道前缀(后接Server名称)
+    gWS_REDIS_RETRY_INTERVAL    = time.Second      // redis订阅断开后的重连间隔
+)
+
+// WebSocket连接管理配置
+type WebSocketHubConfig struct {
+    SendQueueSize  int                                                   // 每个连接的发送队列长度，默认256
+    WriteTimeout   time.Duration                                         // 单条消息写超时时间，默认10秒
+    PingInterval   time.Duration                                         // 服务端发送ping的间隔，默认30秒，超过2倍间隔未收到pong时断开连接
+    MaxMessageSize int64                                                 // 客户端消息最大长度(byte)，默认不限制
+    OnConnect      func(c *WebSocketClient)                              // 连接建立回调
+    OnDisconnect   func(c *WebSocketClient)                              // 连接断开回调
+    OnMessage      func(c *WebSocketClient, messageType int, data []byte) // 收到客户端消息回调
+    Redis          *gredis.Config                                        // 跨实例广播使用的redis配置，为nil时只在当前实例内广播
+    RedisChannel   string                                                // 跨实例广播使用的redis频道，默认为gf:ws:hub:Server名称
+}
+
+// WebSocket连接管理对象(每个Server一个)
+type WebSocketHub struct {
+    mu      sync.RWMutex                             // 连接及房间读写锁
+    server  *Server                                  // 所属Server
+    config  WebSocketHubConfig                       // 配置
+    node    string                                   // 当前实例ID，用于跨实例广播时忽略自身发布的消息
+    clients map[*WebSocketClient]struct{}            // 所有连接
+    rooms   map[string]map[*WebSocketClient]struct{} // 房间连接
+    closed  bool                                     // 是否已关闭
+}
+
+// WebSocket连接对象
+type WebSocketClient struct {
+    *WebSocket                     // 底层连接
+    Id      string                 // 连接ID(同请求ID)
+    Request *Request               // 建立连接的请求对象
+    Param   interface{}            // 开发者自定义参数
+    hub     *WebSocketHub          // 所属连接管理对象
+    mu      sync.RWMutex           // 发送队列锁
+    send    chan wsHubMessage      // 发送队列
+    rooms   map[string]struct{}    // 已加入的房间(由hub.mu保护)
+    closed  bool                   // 是否已关闭
+}
+
+// 待发送的消息
+type wsHubMessage struct {
+    Type int    `json:"type"` // 消息类型，WS_MSG_TEXT/WS_MSG_BINARY
+    Data []byte `json:"data"` // 消息内容
+}
+
+// 跨实例广播的消息
+type wsHubRedisMessage struct {
+    Node string `json:"node"` // 发布消息的实例ID
+    Room string `json:"room"` // 房间名称，为空表示广播给所有连接
+    wsHubMessage
+}
+
+// 连接发送队列已满
+var ErrWebSocketQueueFull = errors.New("websocket send queue is full")
+
+// 连接已关闭
+var ErrWebSocketClosed    = errors.New("websocket connection is closed")
+
+// 开启WebSocket连接管理，同一Server重复调用时返回已有的连接管理对象(配置不会改变)，
+// 开启后通过BindHandler(pattern, hub.Serve)注册WebSocket服务。
+func (s *Server) EnableWebSocketHub(config...WebSocketHubConfig) *WebSocketHub {
+    s.wsHubMu.Lock()
+    defer s.wsHubMu.Unlock()
+    if s.wsHub != nil {
+        return s.wsHub
+    }
+    c := WebSocketHubConfig{}
+    if len(config) > 0 {
+        c = config[0]
+    }
+    if c.SendQueueSize <= 0 {
+        c.SendQueueSize = gDEFAULT_WS_SEND_QUEUE_SIZE
+    }
+    if c.WriteTimeout <= 0 {
+        c.WriteTimeout = gDEFAULT_WS_WRITE_TIMEOUT
+    }
+    if c.PingInterval <= 0 {
+        c.PingInterval = gDEFAULT_WS_PING_INTERVAL
+    }
+    if c.RedisChannel == "" {
+        c.RedisChannel = gDEFAULT_WS_REDIS_CHANNEL + ":" + s.name
+    }
+    s.wsHub = &WebSocketHub {
+        server  : s,
+        config  : c,
+        node    : newTraceId(8),
+        clients : make(map[*WebSocketClient]struct{}),
+        rooms   : make(map[string]map[*WebSocketClient]struct{}),
+    }
+    if c.Redis != nil {
+        go s.wsHub.subscribeLoop()
+    }
+    return s.wsHub
+}
+
+// 获取WebSocket连接管理对象，未开启时返回nil
+func (s *Server) GetWebSocketHub() *WebSocketHub {
+    s.wsHubMu.RLock()
+    defer s.wsHubMu.RUnlock()
+    return s.wsHub
+}
+
+// WebSocket服务方法，将请求升级为WebSocket连接并加入连接管理，阻塞直到连接断开
+func (h *WebSocketHub) Serve(r *Request) {
+    ws, err := r.WebSocket()
+    if err != nil {
+        glog.Error(err)
+        return
+    }
+    c := &WebSocketClient {
+        WebSocket : ws,
+        Id        : r.GetRequestId(),
+        Request   : r,
+        hub       : h,
+        send      : make(chan wsHubMessage, h.config.SendQueueSize),
+        rooms     : make(map[string]struct{}),
+    }
+    if !h.add(c) {
+        ws.Close()
+        return
+    }
+    if h.config.OnConnect != nil {
+        h.config.OnConnect(c)
+    }
+    done := make(chan struct{})
+    go func() {
+        c.writeLoop()
+        // 写入结束后关闭底层连接，使读取循环立即退出
+        ws.Close()
+        close(done)
+    }()
+    c.readLoop()
+    c.Close()
+    <- done
+    if h.config.OnDisconnect != nil {
+        h.config.OnDisconnect(c)
+    }
+}
+
+// 添加连接
+func (h *WebSocketHub) add(c *WebSocketClient) bool {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    if h.closed {
+        return false
+    }
+    h.clients[c] = struct{}{}
+    return true
+}
+
+// 移除连接(同时退出所有房间)
+func (h *WebSocketHub) remove(c *WebSocketClient) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    delete(h.clients, c)
+    for room, _ := range c.rooms {
+        if m, ok := h.rooms[room]; ok {
+            delete(m, c)
+            if len(m) == 0 {
+                delete(h.rooms, room)
+            }
+        }
+    }
+}
+
+// 获取当前连接数
+func (h *WebSocketHub) Count() int {
+    h.mu.RLock()
+    defer h.mu.RUnlock()
+    return len(h.clients)
+}
+
+// 获取所有连接
+func (h *WebSocketHub) Clients() []*WebSocketClient {
+    h.mu.RLock()
+    defer h.mu.RUnlock()
+    array := make([]*WebSocketClient, 0, len(h.clients))
+    for c, _ := range h.clients {
+        array = append(array, c)
+    }
+    return array
+}
+
+// 获取房间内的所有连接
+func (h *WebSocketHub) RoomClients(room string) []*WebSocketClient {
+    h.mu.RLock()
+    defer h.mu.RUnlock()
+    array := make([]*WebSocketClient, 0, len(h.rooms[room]))
+    for c, _ := range h.rooms[room] {
+        array = append(array, c)
+    }
+    return array
+}
+
+// 获取当前所有房间名称(按名称排序)
+func (h *WebSocketHub) Rooms() []string {
+    h.mu.RLock()
+    defer h.mu.RUnlock()
+    array := make([]string, 0, len(h.rooms))
+    for room, _ := range h.rooms {
+        array = append(array, room)
+    }
+    sort.Strings(array)
+    return array
+}
+
+// 广播消息给所有连接(开启跨实例广播时同时发送给其他实例的连接)，
+// 发送队列已满的连接(消费过慢)将会被断开，防止影响其他连接。
+func (h *WebSocketHub) Broadcast(messageType int, data []byte) {
+    h.BroadcastRoom("", messageType, data)
+}
+
+// 广播消息给房间内的所有连接，room为空时表示所有连接
+func (h *WebSocketHub) BroadcastRoom(room string, messageType int, data []byte) {
+    message := wsHubMessage{ Type : messageType, Data : data }
+    h.broadcast(room, message)
+    h.publish(room, message)
+}
+
+// 在当前实例内广播消息
+func (h *WebSocketHub) broadcast(room string, message wsHubMessage) {
+    var clients []*WebSocketClient
+    if room == "" {
+        clients = h.Clients()
+    } else {
+        clients = h.RoomClients(room)
+    }
+    for _, c := range clients {
+        if err := c.push(message); err == ErrWebSocketQueueFull {
+            c.Close()
+        }
+    }
+}
+
+// 关闭连接管理，断开所有连接并停止跨实例广播订阅
+func (h *WebSocketHub) Close() {
+    h.mu.Lock()
+    h.closed = true
+    h.mu.Unlock()
+    // 订阅连接阻塞在消息读取中，不能并发关闭，这里发布一条空消息唤醒订阅协程使其自行退出
+    h.publish("", wsHubMessage{})
+    for _, c := range h.Clients() {
+        c.Close()
+    }
+}
+
+// 是否已关闭
+func (h *WebSocketHub) isClosed() bool {
+    h.mu.RLock()
+    defer h.mu.RUnlock()
+    return h.closed
+}
+
+// 发布跨实例广播消息
+func (h *WebSocketHub) publish(room string, message wsHubMessage) {
+    if h.config.Redis == nil {
+        return
+    }
+    b, err := json.Marshal(wsHubRedisMessage{ Node : h.node, Room : room, wsHubMessage : message })
+    if err != nil {
+        glog.Error(err)
+        return
+    }
+    redis := gredis.New(*h.config.Redis)
+    defer redis.Close()
+    if _, err := redis.Do("PUBLISH", h.config.RedisChannel, b); err != nil {
+        glog.Error("websocket hub publish error:", err)
+    }
+}
+
+// 订阅跨实例广播消息，连接断开时自动重连
+func (h *WebSocketHub) subscribeLoop() {
+    for !h.isClosed() {
+        if err := h.subscribe(); err != nil && !h.isClosed() {
+            glog.Error("websocket hub subscribe error:", err)
+            time.Sleep(gWS_REDIS_RETRY_INTERVAL)
+        }
+    }
+}
+
+// 订阅跨实例广播消息，并在当前实例内广播其他实例发布的消息
+func (h *WebSocketHub) subscribe() error {
+    redis := gredis.New(*h.config.Redis)
+    defer redis.Close()
+    if _, err := redis.Do("SUBSCRIBE", h.config.RedisChannel); err != nil {
+        return err
+    }
+    for {
+        reply, err := redis.Receive()
+        if err != nil {
+            return err
+        }
+        if h.isClosed() {
+            return nil
+        }
+        // 消息格式：["message", channel, data]
+        array, ok := reply.([]interface{})
+        if !ok || len(array) != 3 {
+            continue
+        }
+        if kind, _ := array[0].([]byte); string(kind) != "message" {
+            continue
+        }
+        data, _ := array[2].([]byte)
+        message := wsHubRedisMessage{}
+        if err := json.Unmarshal(data, &message); err != nil {
+            glog.Error(err)
+            continue
+        }
+        // 消息类型为0的是关闭时用于唤醒订阅协程的空消息
+        if message.Node != h.node && message.Type != 0 {
+            h.broadcast(message.Room, message.wsHubMessage)
+        }
+    }
+}
+
+// 将消息加入连接的发送队列，队列已满时返回ErrWebSocketQueueFull
+func (c *WebSocketClient) Send(messageType int, data []byte) error {
+    return c.push(wsHubMessage{ Type : messageType, Data : data })
+}
+
+// 发送文本消息
+func (c *WebSocketClient) SendText(text string) error {
+    return c.Send(WS_MSG_TEXT, []byte(text))
+}
+
+// 发送JSON消息
+func (c *WebSocketClient) SendJson(value interface{}) error {
+    b, err := json.Marshal(value)
+    if err != nil {
+        return err
+    }
+    return c.Send(WS_MSG_TEXT, b)
+}
+
+// 加入房间
+func (c *WebSocketClient) Join(room string) {
+    c.hub.mu.Lock()
+    defer c.hub.mu.Unlock()
+    if _, ok := c.hub.clients[c]; !ok {
+        return
+    }
+    if _, ok := c.hub.rooms[room]; !ok {
+        c.hub.rooms[room] = make(map[*WebSocketClient]struct{})
+    }
+    c.hub.rooms[room][c] = struct{}{}
+    c.rooms[room]        = struct{}{}
+}
+
+// 退出房间
+func (c *WebSocketClient) Leave(room string) {
+    c.hub.mu.Lock()
+    defer c.hub.mu.Unlock()
+    if m, ok := c.hub.rooms[room]; ok {
+        delete(m, c)
+        if len(m) == 0 {
+            delete(c.hub.rooms, room)
+        }
+    }
+    delete(c.rooms, room)
+}
+
+// 获取已加入的房间名称(按名称排序)
+func (c *WebSocketClient) Rooms() []string {
+    c.hub.mu.RLock()
+    defer c.hub.mu.RUnlock()
+    array := make([]string, 0, len(c.rooms))
+    for room, _ := range c.rooms {
+        array = append(array, room)
+    }
+    sort.Strings(array)
+    return array
+}
+
+// 关闭连接，队列中未发送的消息会继续发送完毕
+func (c *WebSocketClient) Close() {
+    c.mu.Lock()
+    if !c.closed {
+        c.closed = true
+        close(c.send)
+    }
+    c.mu.Unlock()
+    c.hub.remove(c)
+}
+
+// 将消息加入发送队列(不阻塞)
+func (c *WebSocketClient) push(message wsHubMessage) error {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    if c.closed {
+        return ErrWebSocketClosed
+    }
+    select {
+        case c.send <- message:
+            return nil
+        default:
+            return ErrWebSocketQueueFull
+    }
+}
+
+// 读取客户端消息，并处理pong心跳
+func (c *WebSocketClient) readLoop() {
+    timeout := 2 * c.hub.config.PingInterval
+    if c.hub.config.MaxMessageSize > 0 {
+        c.SetReadLimit(c.hub.config.MaxMessageSize)
+    }
+    c.SetReadDeadline(time.Now().Add(timeout))
+    c.SetPongHandler(func(string) error {
+        return c.SetReadDeadline(time.Now().Add(timeout))
+    })
+    for {
+        messageType, data, err := c.ReadMessage()
+        if err != nil {
+            return
+        }
+        c.SetReadDeadline(time.Now().Add(timeout))
+        if c.hub.config.OnMessage != nil {
+            c.hub.config.OnMessage(c, messageType, data)
+        }
+    }
+}
+
+// 发送队列消息，并定时发送ping心跳
+func (c *WebSocketClient) writeLoop() {
+    ticker := time.NewTicker(c.hub.config.PingInterval)
+    defer ticker.Stop()
+    for {
+        select {
+            case message, ok := <- c.send:
+                c.SetWriteDeadline(time.Now().Add(c.hub.config.WriteTimeout))
+                if !ok {
+                    c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+                    return
+                }
+                if err := c.WriteMessage(message.Type, message.Data); err != nil {
+                    c.Close()
+                    return
+                }
+            case <- ticker.C:
+                deadline := time.Now().Add(c.hub.config.WriteTimeout)
+                if err := c.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+                    c.Close()
+                    return
+                }
+        }
+    }
+}
@@ -33,6 +33,8 @@ type Cache struct {
     eksets     map[int64]*gset.StringSet // 分组过期时间对应的键名列表(用于自动过期快速删除)，键值为秒级时间戳
     eventQueue *gqueue.Queue             // 异步处理队列
     stopEvents chan struct{}             // 关闭时间通知
+    hits       *gtype.Int64              // Get命中次数
+    misses     *gtype.Int64              // Get未命中次数
 }
 
 // 缓存数据项
@@ -60,6 +62,8 @@ func New() *Cache {
         eksets     : make(map[int64]*gset.StringSet),
         eventQueue : gqueue.New(),
         stopEvents : make(chan struct{}, 2),
+        hits       : gtype.NewInt64(),
+        misses     : gtype.NewInt64(),
     }
     go c.autoSyncLoop()
     go c.autoClearLoop()
@@ -111,6 +115,11 @@ func Size() int {
     return cache.Size()
 }
 
+// (使用全局KV缓存对象)获得Get命中及未命中次数
+func Stats() (hits, misses int64) {
+    return cache.Stats()
+}
+
 // 设置缓存池大小，内部依靠LRU算法进行缓存淘汰处理
 func (c *Cache) SetCap(cap int) {
     c.cap.Set(cap)
@@ -187,6 +196,16 @@ func (c *Cache) BatchSet(data map[string]interface{}, expire int)  {
 
 // 获取指定键名的值
 func (c *Cache) Get(key string) interface{} {
+    if v := c.get(key); v != nil {
+        c.hits.Add(1)
+        return v
+    }
+    c.misses.Add(1)
+    return nil
+}
+
+// 获取指定键名的值(不计入命中统计)
+func (c *Cache) get(key string) interface{} {
     c.dmu.RLock()
     item, ok := c.data[key]
     c.dmu.RUnlock()
@@ -198,7 +217,12 @@ func (c *Cache) Get(key string) interface{} {
 
 // 是否存在指定的键名，true表示存在，false表示不存在。
 func (c *Cache) Contains(key string) bool {
-    return c.Get(key) != nil
+    return c.get(key) != nil
+}
+
+// 获得Get命中及未命中次数，可用于计算缓存命中率
+func (c *Cache) Stats() (hits, misses int64) {
+    return c.hits.Val(), c.misses.Val()
 }
 
 // 删除指定键值对
@@ -12,6 +12,7 @@ package glog
 import (
     "io"
     "sync"
+    "context"
     "gitee.com/johng/gf/g/container/gtype"
 )
 
@@ -24,6 +25,7 @@ type Logger struct {
     btSkip       *gtype.Int          // 错误产生时的backtrace回调信息skip条数
     btEnabled    *gtype.Bool         // 是否当打印错误时同时开启backtrace打印
     alsoStdPrint *gtype.Bool         // 控制台打印开关，当输出到文件/自定义输出时也同时打印到终端
+    prefix       *gtype.String       // 日志内容前缀(输出在时间之后)，例如用于输出请求ID
 }
 
 const (
@@ -113,6 +115,16 @@ func StdPrint(enabled bool) *Logger {
     return logger.StdPrint(enabled)
 }
 
+// 设置日志内容前缀
+func Prefix(prefix string) *Logger {
+    return logger.Prefix(prefix)
+}
+
+// 使用上下文中的日志前缀(通过WithPrefix设置)
+func Ctx(ctx context.Context) *Logger {
+    return logger.Ctx(ctx)
+}
+
 func Print(v ...interface{}) {
     logger.Print(v ...)
 }
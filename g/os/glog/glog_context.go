@@ -0,0 +1,29 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 日志上下文.
+
+package glog
+
+import "context"
+
+// 上下文中日志前缀的键名类型
+type contextPrefixKey struct{}
+
+// 返回携带日志前缀(例如请求ID)的上下文，通过glog.Ctx(ctx)输出的日志会在时间之后带上该前缀
+func WithPrefix(ctx context.Context, prefix string) context.Context {
+    return context.WithValue(ctx, contextPrefixKey{}, prefix)
+}
+
+// 获取上下文中的日志前缀，不存在时返回空字符串
+func GetPrefixFromContext(ctx context.Context) string {
+    if ctx == nil {
+        return ""
+    }
+    if prefix, ok := ctx.Value(contextPrefixKey{}).(string); ok {
+        return prefix
+    }
+    return ""
+}
@@ -46,6 +46,7 @@ func New() *Logger {
         btSkip       : gtype.NewInt(),
         btEnabled    : gtype.NewBool(true),
         alsoStdPrint : gtype.NewBool(true),
+        prefix       : gtype.NewString(),
     }
 }
 
@@ -59,9 +60,20 @@ func (l *Logger) Clone() *Logger {
         btSkip       : l.btSkip.Clone(),
         btEnabled    : l.btEnabled.Clone(),
         alsoStdPrint : l.alsoStdPrint.Clone(),
+        prefix       : l.prefix.Clone(),
     }
 }
 
+// 设置日志内容前缀
+func (l *Logger) SetPrefix(prefix string) {
+    l.prefix.Set(prefix)
+}
+
+// 获取日志内容前缀
+func (l *Logger) GetPrefix() string {
+    return l.prefix.Val()
+}
+
 // 设置日志记录等级
 func (l *Logger) SetLevel(level int) {
     l.level.Set(level)
@@ -211,6 +223,9 @@ func (l *Logger) GetBacktrace(skip...int) string {
 }
 
 func (l *Logger) format(s string) string {
+    if prefix := l.prefix.Val(); prefix != "" {
+        return time.Now().Format("2006-01-02 15:04:05.000 ") + prefix + " " + s
+    }
     return time.Now().Format("2006-01-02 15:04:05.000 ") + s
 }
 
@@ -6,7 +6,10 @@
 
 package glog
 
-import "gitee.com/johng/gf/g/os/gfile"
+import (
+    "context"
+    "gitee.com/johng/gf/g/os/gfile"
+)
 
 // 链式操作，设置下一次输出的日志分类(可以按照文件目录层级设置)，在当前logpath或者当前工作目录下创建category目录，
 // 这是一个链式操作，可以设置多个分类，将会创建层级的日志分类目录。
@@ -62,4 +65,24 @@ func (l *Logger) StdPrint(enabled bool) *Logger {
     }
     logger.SetStdPrint(enabled)
     return logger
-}
\ No newline at end of file
+}
+
+// 设置日志内容前缀
+func (l *Logger) Prefix(prefix string) *Logger {
+    logger := (*Logger)(nil)
+    if l.pr == nil {
+        logger = l.Clone()
+    } else {
+        logger = l
+    }
+    logger.SetPrefix(prefix)
+    return logger
+}
+
+// 使用上下文中的日志前缀(通过WithPrefix设置)，上下文中没有前缀时不做修改
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+    if prefix := GetPrefixFromContext(ctx); prefix != "" {
+        return l.Prefix(prefix)
+    }
+    return l
+}
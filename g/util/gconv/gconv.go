@@ -39,6 +39,10 @@ func Convert(i interface{}, t string, params...interface{}) interface{} {
         case "bool":            return Bool(i)
         case "string":          return String(i)
         case "[]byte":          return Bytes(i)
+        case "[]int":           return Ints(i)
+        case "[]float64":       return Floats(i)
+        case "[]string":        return Strings(i)
+        case "[]interface {}":  return Interfaces(i)
         case "time.Time":
             if len(params) > 0 {
                 return Time(i, String(params[0]))
@@ -127,6 +131,57 @@ func Strings(i interface{}) []string {
     return []string{fmt.Sprintf("%v", i)}
 }
 
+// 将变量i转换为[]int类型
+func Ints(i interface{}) []int {
+    if i == nil {
+        return nil
+    }
+    if r, ok := i.([]int); ok {
+        return r
+    }
+    array := Interfaces(i)
+    ints  := make([]int, len(array))
+    for k, v := range array {
+        ints[k] = Int(v)
+    }
+    return ints
+}
+
+// 将变量i转换为[]float64类型
+func Floats(i interface{}) []float64 {
+    if i == nil {
+        return nil
+    }
+    if r, ok := i.([]float64); ok {
+        return r
+    }
+    array  := Interfaces(i)
+    floats := make([]float64, len(array))
+    for k, v := range array {
+        floats[k] = Float64(v)
+    }
+    return floats
+}
+
+// 将变量i转换为[]interface{}类型，如果i不是slice/array类型，那么返回只包含i的数组
+func Interfaces(i interface{}) []interface{} {
+    if i == nil {
+        return nil
+    }
+    if r, ok := i.([]interface{}); ok {
+        return r
+    }
+    rv := reflect.ValueOf(i)
+    if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+        array := make([]interface{}, rv.Len())
+        for k := 0; k < rv.Len(); k++ {
+            array[k] = rv.Index(k).Interface()
+        }
+        return array
+    }
+    return []interface{}{i}
+}
+
 //false: "", 0, false, off
 func Bool(i interface{}) bool {
     if i == nil {
@@ -304,7 +359,18 @@ func Float64 (i interface{}) float64 {
 // 1、第二个参数为struct对象指针；
 // 2、struct对象的**公开属性(首字母大写)**才能被映射赋值；
 // 3、map中的键名可以为小写，映射转换时会自动将键名首字母转为大写做匹配映射，如果无法匹配则忽略；
+// 4、键值为map(或者map数组)且属性为struct(或者struct数组)时递归映射，其他无法转换为属性类型的键值忽略；
 func MapToStruct(params map[string]interface{}, object interface{}, mapping...map[string]string) error {
+    return mapToStruct(params, object, false, mapping...)
+}
+
+// 同MapToStruct，但是键值无法转换为对应属性的类型时返回错误，用于需要严格校验输入的场景(如ghttp.Request.Parse)
+func MapToStructStrict(params map[string]interface{}, object interface{}, mapping...map[string]string) error {
+    return mapToStruct(params, object, true, mapping...)
+}
+
+// 映射params到struct对象，strict为true时无法转换的键值返回错误，否则忽略
+func mapToStruct(params map[string]interface{}, object interface{}, strict bool, mapping...map[string]string) error {
     tagmap := make(map[string]string)
     fields := structs.Fields(object)
     // 将struct中定义的属性转换名称构建称tagmap
@@ -322,7 +388,9 @@ func MapToStruct(params map[string]interface{}, object interface{}, mapping...ma
         for mappingk, mappingv := range mapping[0] {
             if v, ok := params[mappingk]; ok {
                 dmap[mappingv] = true
-                bindVarToStruct(elem, mappingv, v)
+                if err := bindVarToStruct(elem, mappingv, v, strict); err != nil {
+                    return err
+                }
             }
         }
     }
@@ -333,7 +401,9 @@ func MapToStruct(params map[string]interface{}, object interface{}, mapping...ma
         }
         if v, ok := params[tagk]; ok {
             dmap[tagv] = true
-            bindVarToStruct(elem, tagv, v)
+            if err := bindVarToStruct(elem, tagv, v, strict); err != nil {
+                return err
+            }
         }
     }
     // 最后按照默认规则进行匹配
@@ -344,23 +414,95 @@ func MapToStruct(params map[string]interface{}, object interface{}, mapping...ma
         }
         // 后续tag逻辑中会处理的key(重复的键名)这里便不处理
         if _, ok := tagmap[mapk]; !ok {
-            bindVarToStruct(elem, name, mapv)
+            if err := bindVarToStruct(elem, name, mapv, strict); err != nil {
+                return err
+            }
         }
     }
     return nil
 }
 
-// 将参数值绑定到对象指定名称的属性上
-func bindVarToStruct(elem reflect.Value, name string, value interface{}) {
+// 将参数值绑定到对象指定名称的属性上，参数值无法转换为属性类型时，strict为true时返回错误，否则忽略
+func bindVarToStruct(elem reflect.Value, name string, value interface{}, strict bool) error {
     structFieldValue := elem.FieldByName(name)
     // 键名与对象属性匹配检测
     if !structFieldValue.IsValid() {
-        return
+        return nil
     }
     // CanSet的属性必须为公开属性(首字母大写)
     if !structFieldValue.CanSet() {
-        return
+        return nil
+    }
+    // 嵌套的struct属性按照子map递归映射
+    if ok, err := bindNestedVar(structFieldValue, value, strict); ok || err != nil {
+        return err
     }
     // 必须将value转换为struct属性的数据类型，这里必须用到gconv包
-    structFieldValue.Set(reflect.ValueOf(Convert(value, structFieldValue.Type().String())))
+    converted := reflect.ValueOf(Convert(value, structFieldValue.Type().String()))
+    // 无法转换的类型(例如复杂的嵌套类型)，防止赋值时panic
+    if !converted.IsValid() || !converted.Type().AssignableTo(structFieldValue.Type()) {
+        if strict {
+            return fmt.Errorf(`cannot convert value of type %T to type %s for attribute "%s"`, value, structFieldValue.Type().String(), name)
+        }
+        return nil
+    }
+    structFieldValue.Set(converted)
+    return nil
+}
+
+// 将map(或者map数组)递归映射到struct(或者struct指针、struct数组)类型的属性上，返回是否已处理
+func bindNestedVar(field reflect.Value, value interface{}, strict bool) (bool, error) {
+    switch v := value.(type) {
+        case map[string]interface{}:
+            if !isStructType(field.Type()) {
+                return false, nil
+            }
+            item, err := newNestedStruct(field.Type(), v, strict)
+            if err != nil {
+                return true, err
+            }
+            field.Set(item)
+            return true, nil
+
+        case []interface{}:
+            if field.Kind() != reflect.Slice || !isStructType(field.Type().Elem()) {
+                return false, nil
+            }
+            slice := reflect.MakeSlice(field.Type(), 0, len(v))
+            for _, e := range v {
+                m, ok := e.(map[string]interface{})
+                if !ok {
+                    if strict {
+                        return true, fmt.Errorf(`cannot convert value of type %T to type %s`, e, field.Type().Elem().String())
+                    }
+                    continue
+                }
+                item, err := newNestedStruct(field.Type().Elem(), m, strict)
+                if err != nil {
+                    return true, err
+                }
+                slice = reflect.Append(slice, item)
+            }
+            field.Set(slice)
+            return true, nil
+    }
+    return false, nil
+}
+
+// 判断是否为可递归映射的struct或者struct指针类型(time.Time除外)
+func isStructType(t reflect.Type) bool {
+    if t.Kind() == reflect.Ptr {
+        t = t.Elem()
+    }
+    return t.Kind() == reflect.Struct && t.String() != "time.Time"
+}
+
+// 创建给定类型(struct或者struct指针)的对象，并将params映射到该对象上
+func newNestedStruct(t reflect.Type, params map[string]interface{}, strict bool) (reflect.Value, error) {
+    if t.Kind() == reflect.Ptr {
+        pointer := reflect.New(t.Elem())
+        return pointer, mapToStruct(params, pointer.Interface(), strict)
+    }
+    pointer := reflect.New(t)
+    return pointer.Elem(), mapToStruct(params, pointer.Interface(), strict)
 }
@@ -0,0 +1,142 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+// 单元测试
+// go test *.go
+
+package gconv_test
+
+import (
+    "testing"
+    "reflect"
+    "gitee.com/johng/gf/g/util/gconv"
+)
+
+func Test_Ints(t *testing.T) {
+    tests := []struct {
+        value  interface{}
+        expect []int
+    } {
+        {nil,                         nil},
+        {[]int{1, 2},                 []int{1, 2}},
+        {[]string{"1", "2", "a"},     []int{1, 2, 0}},
+        {[]interface{}{1.5, "3", 4},  []int{1, 3, 4}},
+        {[2]int64{5, 6},              []int{5, 6}},
+        {"7",                         []int{7}},
+    }
+    for _, test := range tests {
+        if r := gconv.Ints(test.value); !reflect.DeepEqual(r, test.expect) {
+            t.Errorf("Ints(%v): expect %v, got %v", test.value, test.expect, r)
+        }
+    }
+}
+
+func Test_Floats(t *testing.T) {
+    tests := []struct {
+        value  interface{}
+        expect []float64
+    } {
+        {nil,                          nil},
+        {[]float64{1.5},               []float64{1.5}},
+        {[]string{"1.5", "2", "a"},    []float64{1.5, 2, 0}},
+        {[]interface{}{1, "3.25"},     []float64{1, 3.25}},
+        {"7.5",                        []float64{7.5}},
+    }
+    for _, test := range tests {
+        if r := gconv.Floats(test.value); !reflect.DeepEqual(r, test.expect) {
+            t.Errorf("Floats(%v): expect %v, got %v", test.value, test.expect, r)
+        }
+    }
+}
+
+func Test_Interfaces(t *testing.T) {
+    tests := []struct {
+        value  interface{}
+        expect []interface{}
+    } {
+        {nil,                      nil},
+        {[]interface{}{1, "a"},    []interface{}{1, "a"}},
+        {[]string{"a", "b"},       []interface{}{"a", "b"}},
+        {[2]int{1, 2},             []interface{}{1, 2}},
+        {1,                        []interface{}{1}},
+    }
+    for _, test := range tests {
+        if r := gconv.Interfaces(test.value); !reflect.DeepEqual(r, test.expect) {
+            t.Errorf("Interfaces(%v): expect %v, got %v", test.value, test.expect, r)
+        }
+    }
+    if r := gconv.Convert([]string{"1", "2"}, "[]int"); !reflect.DeepEqual(r, []int{1, 2}) {
+        t.Errorf("Convert to []int: got %v", r)
+    }
+}
+
+func Test_MapToStruct(t *testing.T) {
+    type Address struct {
+        City string
+    }
+    type User struct {
+        Id      int
+        Name    string `gconv:"username"`
+        Scores  []int
+        Address Address
+    }
+    user   := new(User)
+    params := map[string]interface{} {
+        "id"       : "1",
+        "username" : "john",
+        "scores"   : []string{"90", "85"},
+    }
+    if err := gconv.MapToStruct(params, user); err != nil {
+        t.Fatal(err)
+    }
+    if user.Id != 1 || user.Name != "john" || !reflect.DeepEqual(user.Scores, []int{90, 85}) {
+        t.Errorf("unexpected struct: %+v", user)
+    }
+    // 嵌套的struct属性递归映射
+    params["address"] = map[string]interface{}{"city" : "beijing"}
+    if err := gconv.MapToStruct(params, user); err != nil || user.Address.City != "beijing" {
+        t.Errorf("nested struct should be mapped, %v, %+v", err, user)
+    }
+    // 无法转换的属性类型：MapToStruct忽略，MapToStructStrict返回错误
+    params["address"] = []interface{}{"beijing"}
+    if err := gconv.MapToStruct(params, user); err != nil {
+        t.Errorf("unconvertible attribute should be ignored, %v", err)
+    }
+    if err := gconv.MapToStructStrict(params, user); err == nil {
+        t.Error("expect error for unconvertible attribute")
+    }
+}
+
+func Test_MapToStructNestedSlice(t *testing.T) {
+    type Item struct {
+        Name  string
+        Count int
+    }
+    type Order struct {
+        Items    []Item
+        Pointers []*Item
+        First    *Item
+    }
+    order := new(Order)
+    items := []interface{} {
+        map[string]interface{}{"name" : "a", "count" : 1},
+        map[string]interface{}{"name" : "b", "count" : "2"},
+    }
+    params := map[string]interface{} {
+        "items"    : items,
+        "pointers" : items,
+        "first"    : map[string]interface{}{"name" : "c"},
+    }
+    if err := gconv.MapToStructStrict(params, order); err != nil {
+        t.Fatal(err)
+    }
+    if len(order.Items) != 2 || order.Items[1].Count != 2 || len(order.Pointers) != 2 || order.Pointers[0].Name != "a" {
+        t.Errorf("unexpected nested slice: %+v", order)
+    }
+    if order.First == nil || order.First.Name != "c" {
+        t.Errorf("unexpected nested struct pointer: %+v", order.First)
+    }
+}
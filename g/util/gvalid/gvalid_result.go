@@ -44,6 +44,11 @@ func (e Error) String() string {
     return strings.Join(e.Strings(), "; ")
 }
 
+// 实现error接口，以便校验错误可以作为error返回
+func (e Error) Error() string {
+    return e.String()
+}
+
 // 只返回错误信息，构造成字符串数组返回
 func (e Error) Strings() []string {
     array := make([]string, 0)
@@ -0,0 +1,66 @@
+package main
+
+import (
+    "fmt"
+    "strings"
+    "database/sql"
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/database/gdb"
+)
+
+// 自定义数据库驱动示例：TiDB兼容MySQL协议，使用MySQL的底层驱动
+type tidb struct {}
+
+func (d *tidb) Open(c *gdb.ConfigNode) (*sql.DB, error) {
+    return sql.Open("mysql", fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", c.User, c.Pass, c.Host, c.Port, c.Name))
+}
+
+func (d *tidb) GetQuoteCharLeft() string {
+    return "`"
+}
+
+func (d *tidb) GetQuoteCharRight() string {
+    return "`"
+}
+
+func (d *tidb) HandleSqlBeforeExec(q *string) *string {
+    return q
+}
+
+func (d *tidb) HandleLimit(q string, start, limit int) string {
+    return fmt.Sprintf("%s LIMIT %d OFFSET %d", q, limit, start)
+}
+
+// 可选实现InsertDriver接口以支持Replace/Save/InsertIgnore操作，不实现时只支持Insert操作
+func (d *tidb) HandleInsert(table string, keys []string, values []string, option uint8, conflict []string) (string, error) {
+    operation := "INSERT"
+    suffix    := ""
+    switch option {
+        case gdb.OPTION_REPLACE:
+            operation = "REPLACE"
+        case gdb.OPTION_IGNORE:
+            operation = "INSERT IGNORE"
+        case gdb.OPTION_SAVE:
+            updates := make([]string, len(keys))
+            for i, k := range keys {
+                updates[i] = fmt.Sprintf("%s=VALUES(%s)", k, k)
+            }
+            suffix = " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ",")
+    }
+    return fmt.Sprintf("%s INTO %s(%s) VALUES%s%s", operation, table, strings.Join(keys, ","), strings.Join(values, ","), suffix), nil
+}
+
+func main() {
+    // 注册后即可在配置的Type中使用
+    gdb.Register("tidb", &tidb{})
+    gdb.AddDefaultConfigNode(gdb.ConfigNode {
+        Host : "127.0.0.1",
+        Port : "4000",
+        User : "root",
+        Pass : "",
+        Name : "test",
+        Type : "tidb",
+    })
+    r, err := g.Database().Table("user").Where("uid>?", 1).Limit(0, 10).Select()
+    fmt.Println(r, err)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+    "fmt"
+    "time"
+    "database/sql"
+    "gitee.com/johng/gf/g/database/gdb"
+)
+
+// 公共字段，嵌套struct的属性会被展开
+type Base struct {
+    Uid     int       `orm:"uid,primary"`
+    Created time.Time `orm:"create_time,omitempty"`
+}
+
+// 数据表user对应的struct
+type User struct {
+    Base
+    Name     string         `orm:"name"`
+    Nickname sql.NullString `orm:"nickname"`
+    Email    *string        `orm:"email,omitempty"`
+    Password string         `orm:"-"`
+}
+
+// orm tag映射示例
+func main() {
+    gdb.AddDefaultConfigNode(gdb.ConfigNode {
+        Host : "127.0.0.1",
+        Port : "3306",
+        User : "root",
+        Pass : "123456",
+        Name : "test",
+        Type : "mysql",
+        Role : "master",
+    })
+    db, err := gdb.New()
+    if err != nil {
+        panic(err)
+    }
+    // 写入struct对象，主键字段uid用于Save操作的冲突判断
+    user := User{Name : "john"}
+    user.Uid     = 1
+    user.Created = time.Now()
+    if _, err := db.Table("user").Data(user).Save(); err != nil {
+        fmt.Println(err)
+    }
+
+    // 查询结果映射到struct对象
+    u := new(User)
+    if err := db.Table("user").Where("uid=?", 1).Struct(u); err != nil {
+        fmt.Println(err)
+    }
+    fmt.Println(u.Uid, u.Name, u.Nickname.Valid, u.Created)
+
+    // 查询结果集映射到struct数组，也可以使用[]User
+    users := make([]*User, 0)
+    if err := db.Table("user").Where("uid>?", 0).OrderBy("uid").Structs(&users); err != nil {
+        fmt.Println(err)
+    }
+    for _, v := range users {
+        fmt.Println(v.Uid, v.Name)
+    }
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+    "time"
+    "gitee.com/johng/gf/g/database/gdb"
+    "gitee.com/johng/gf/g/util/gutil"
+)
+
+// 一主多从，读操作按照Priority权重分配到健康的从库，从库全部不可用时使用主库
+func main() {
+    gdb.AddDefaultConfigGroup(gdb.ConfigGroup {
+        {Host : "192.168.1.100", Port : "3306", User : "root", Pass : "123456", Name : "test", Type : "mysql", Role : "master"},
+        {Host : "192.168.1.101", Port : "3306", User : "root", Pass : "123456", Name : "test", Type : "mysql", Role : "slave", Priority : 1},
+        {Host : "192.168.1.102", Port : "3306", User : "root", Pass : "123456", Name : "test", Type : "mysql", Role : "slave", Priority : 2},
+    })
+    // 每5秒检查一次从库健康状态
+    gdb.SetHealthCheckInterval(5 * time.Second)
+    db, err := gdb.New()
+    if err != nil {
+        panic(err)
+    }
+    db.Table("user").Data(gdb.Map{"uid" : 1, "name" : "john"}).Save()
+
+    // 写入后立即读取，强制使用主库，避免主从同步延迟
+    r, _ := db.Table("user").Master().Where("uid=?", 1).One()
+    gutil.Dump(r.ToMap())
+
+    // 普通读操作使用从库
+    r, _  = db.Table("user").Where("uid=?", 1).One()
+    gutil.Dump(r.ToMap())
+}
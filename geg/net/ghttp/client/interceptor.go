@@ -0,0 +1,41 @@
+package main
+
+import (
+    "fmt"
+    "time"
+    "net/http"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/net/ghttp"
+    "gitee.com/johng/gf/g/crypto/gmd5"
+    "gitee.com/johng/gf/g/util/gconv"
+)
+
+func main() {
+    c := ghttp.NewClient()
+    // 请求签名
+    c.Use(func(req *http.Request, next ghttp.ClientHandler) (*http.Response, error) {
+        timestamp := gconv.String(time.Now().Unix())
+        req.Header.Set("X-Timestamp", timestamp)
+        req.Header.Set("X-Sign",      gmd5.EncryptString("secret" + req.URL.Path + timestamp))
+        return next(req)
+    })
+    // 请求日志
+    c.Use(func(req *http.Request, next ghttp.ClientHandler) (*http.Response, error) {
+        start     := time.Now()
+        resp, err := next(req)
+        if err != nil {
+            glog.Printfln("%s %s error: %v", req.Method, req.URL, err)
+        } else {
+            glog.Printfln("%s %s %d %v", req.Method, req.URL, resp.StatusCode, time.Since(start))
+        }
+        return resp, err
+    })
+    // 首次运行时录制返回结果，之后直接从录制文件返回，不再访问网络
+    c.SetRecorder("/tmp/gf-client-fixtures", ghttp.CLIENT_RECORD_MODE_AUTO)
+    if r, e := c.Get("http://127.0.0.1:8199/"); e != nil {
+        glog.Error(e)
+    } else {
+        // 读取完成后自动关闭链接
+        fmt.Println(r.ReadAllString())
+    }
+}
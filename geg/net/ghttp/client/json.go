@@ -0,0 +1,36 @@
+package main
+
+import (
+    "fmt"
+    "time"
+    "context"
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+func main() {
+    c := ghttp.NewClient()
+    // 网络错误或者服务端返回502/503/504时最多重试3次，间隔依次为100ms/200ms/400ms
+    c.SetRetry(3, 100*time.Millisecond)
+
+    // 返回内容解析为gjson.Json对象
+    if j, e := c.GetJson("http://127.0.0.1:8199/user?id=1"); e != nil {
+        glog.Error(e)
+    } else {
+        fmt.Println(j.GetString("name"))
+    }
+
+    // 以JSON方式提交数据，返回内容解析到struct
+    user := struct {
+        Id   int
+        Name string
+    }{}
+    ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+    defer cancel()
+    if e := c.WithContext(ctx).PostJsonTo("http://127.0.0.1:8199/user", g.Map{"name" : "john"}, &user); e != nil {
+        glog.Error(e)
+    } else {
+        fmt.Println(user)
+    }
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+    "fmt"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+func main() {
+    c := ghttp.NewClient()
+    if e := c.SetProxy("socks5://127.0.0.1:1080"); e != nil {
+        glog.Error(e)
+        return
+    }
+    if r, e := c.Get("https://www.baidu.com"); e != nil {
+        glog.Error(e)
+    } else {
+        defer r.Close()
+        fmt.Println(r.StatusCode)
+    }
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+    "fmt"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+func main() {
+    r, e := ghttp.NewClient().PostMultipart("http://127.0.0.1:8199/upload",
+        map[string]string{"name" : "john"},
+        map[string][]string{"upload-file" : {"/tmp/1.txt", "/tmp/2.txt"}},
+    )
+    if e != nil {
+        glog.Error(e)
+    } else {
+        fmt.Println(string(r.ReadAll()))
+        r.Close()
+    }
+}
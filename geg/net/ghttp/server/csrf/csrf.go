@@ -0,0 +1,32 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+func main() {
+    s := g.Server()
+    // 开启CSRF防护，/api/下的接口供第三方调用，不做CSRF校验
+    s.EnableCsrf(ghttp.CsrfConfig {
+        Exempts : []string{"/api/*any"},
+    })
+    s.BindHandler("/form", func(r *ghttp.Request) {
+        r.Response.WriteTplContent(`
+            <form method="post" action="/submit">
+                {{csrf_field}}
+                <input type="text" name="name">
+                <input type="submit">
+            </form>
+            <script>var csrfToken = "{{csrf_token}}";</script>
+        `, g.Map{})
+    })
+    s.BindHandler("POST:/submit", func(r *ghttp.Request) {
+        r.Response.Write("hello ", r.GetPostString("name"))
+    })
+    s.BindHandler("POST:/api/notify", func(r *ghttp.Request) {
+        r.Response.Write("ok")
+    })
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+type Order struct {}
+
+func (o *Order) List(r *ghttp.Request) {
+    r.Response.Write("order list")
+}
+
+func main() {
+    s := g.Server()
+    // 分组路由，该分组下的路由规则都会自动加上/api/v1前缀
+    v1 := s.Group("/api/v1")
+    v1.BindHookHandler("/*", ghttp.HOOK_BEFORE_SERVE, func(r *ghttp.Request) {
+        r.Response.Header().Set("X-Api-Version", "v1")
+    })
+    v1.BindHandler("/user/:id", func(r *ghttp.Request) {
+        r.Response.Write("user: ", r.Get("id"))
+    })
+    v1.BindHandler("post:/user", func(r *ghttp.Request) {
+        r.Response.Write("create user")
+    })
+    // 嵌套分组: /api/v1/shop/order/list
+    shop := v1.Group("/shop")
+    shop.BindObject("/order", &Order{})
+    s.SetPort(8199)
+    s.Run()
+}
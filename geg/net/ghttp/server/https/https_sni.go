@@ -0,0 +1,31 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// 多证书(SNI)、证书热更新及HTTP/2示例，
+// 证书文件更新后无需重启服务，新的连接将自动使用新证书
+func main() {
+    s := ghttp.GetServer()
+    s.BindHandler("/", func(r *ghttp.Request){
+        r.Response.Writeln(r.Host, r.Proto)
+    })
+    // 默认证书，没有匹配到域名证书时使用
+    s.EnableHTTPS("/home/john/temp/server.crt", "/home/john/temp/server.key")
+    // 域名证书，支持泛域名
+    if err := s.Domain("a.com,www.a.com").EnableHTTPS("/home/john/temp/a.com.crt", "/home/john/temp/a.com.key"); err != nil {
+        glog.Error(err)
+    }
+    if err := s.Domain("*.b.com").EnableHTTPS("/home/john/temp/b.com.crt", "/home/john/temp/b.com.key"); err != nil {
+        glog.Error(err)
+    }
+    // HTTPS开启HTTP/2，HTTP服务可开启h2c供内部服务使用(需要Go 1.24及以上版本)
+    s.SetHTTP2Enabled(true)
+    s.SetH2CEnabled(true)
+    s.SetHTTPSPort(8199)
+    s.SetPort(8200)
+    s.EnableAdmin()
+    s.Run()
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// 配置文件config.toml示例：
+// [server]
+//     DenyIps        = ["10.0.0.0/8", "192.168.1.*", "2001:db8::/32"]
+//     AllowIps       = []
+//     TrustedProxies = ["127.0.0.1", "172.16.0.0/12"]
+func main() {
+    s := g.Server()
+    s.BindHandler("/", func(r *ghttp.Request) {
+        r.Response.Write("your ip: ", r.GetClientIp())
+    })
+    // 从配置文件加载IP访问控制规则，配置文件修改后自动生效
+    if err := s.SetIpRulesFromConfig(g.Config(), "server"); err != nil {
+        // 也可以直接设置
+        s.SetDenyIps([]string{"10.0.0.0/8", "192.168.1.*"})
+        s.SetTrustedProxies([]string{"127.0.0.1"})
+    }
+    s.SetPort(8199)
+    s.Run()
+}
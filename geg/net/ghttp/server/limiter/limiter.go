@@ -0,0 +1,41 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+    "gitee.com/johng/gf/g/database/gredis"
+)
+
+func main() {
+    s := g.Server()
+    // 全局限制：每个IP每秒最多100个请求
+    s.SetLimiter(ghttp.LimiterConfig {
+        Rate  : 100,
+        Burst : 200,
+    })
+    // 登录接口：按照Session限制，每秒1次，允许突发5次，计数器存放在Redis中供多个实例共享
+    s.BindLimiter("POST:/login", ghttp.LimiterConfig {
+        Rate  : 1,
+        Burst : 5,
+        KeyBy : ghttp.LIMITER_KEY_SESSION,
+        Redis : &gredis.Config{ Host : "127.0.0.1", Port : 6379 },
+    })
+    // 导出接口：按照自定义的用户标识限制并发数
+    s.BindLimiter("/export", ghttp.LimiterConfig {
+        Concurrency : 1,
+        KeyFunc     : func(r *ghttp.Request) string {
+            return r.Header.Get("X-User-Id")
+        },
+    })
+    s.BindHandler("/login", func(r *ghttp.Request) {
+        r.Response.Write("login")
+    })
+    s.BindHandler("/export", func(r *ghttp.Request) {
+        r.Response.Write("export")
+    })
+    s.BindStatusHandler(429, func(r *ghttp.Request) {
+        r.Response.Write("too many requests, please retry later")
+    })
+    s.SetPort(8199)
+    s.Run()
+}
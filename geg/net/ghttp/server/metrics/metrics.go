@@ -0,0 +1,17 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// 监控指标示例，Prometheus抓取地址：http://127.0.0.1:8199/metrics
+func main() {
+    s := g.Server()
+    s.BindHandler("/user/:id", func(r *ghttp.Request){
+        r.Response.Writeln("user", r.Get("id"))
+    })
+    s.EnableMetrics()
+    s.SetPort(8199)
+    s.Run()
+}
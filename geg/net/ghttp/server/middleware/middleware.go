@@ -0,0 +1,32 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/os/gtime"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+func main() {
+    s := g.Server()
+    // 全局中间件：统计请求执行时间
+    s.BindMiddlewareDefault(func(r *ghttp.Request) {
+        start := gtime.Microsecond()
+        r.Middleware.Next()
+        glog.Printfln("%s %s %.3fms", r.Method, r.URL.Path, float64(gtime.Microsecond() - start)/1000)
+    })
+    // 分组中间件：权限校验，校验失败时不调用Next()，直接返回
+    api := s.Group("/api")
+    api.Middleware(func(r *ghttp.Request) {
+        if r.Header.Get("Token") == "" {
+            r.Response.WriteStatus(403)
+            return
+        }
+        r.Middleware.Next()
+    })
+    api.BindHandler("/user/:id", func(r *ghttp.Request) {
+        r.Response.Write("user: ", r.Get("id"))
+    })
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+type UserReq struct {
+    Id       int    `params:"id"`
+    Passport string `params:"passport" gvalid:"required|length:6,16"`
+    Email    string `params:"email"    gvalid:"email"`
+}
+
+type User struct {
+    Id       int    `json:"id"`
+    Passport string `json:"passport"`
+    Email    string `json:"email"`
+}
+
+type Api struct {}
+
+func (a *Api) Show(r *ghttp.Request) {
+    r.Response.WriteJson(User{Id : r.GetInt("id")})
+}
+
+func (a *Api) Save(r *ghttp.Request) {
+    req := new(UserReq)
+    if err := r.Parse(req); err != nil {
+        r.Response.WriteStatus(400, err.Error())
+        return
+    }
+    r.Response.WriteJson(User{Id : req.Id, Passport : req.Passport, Email : req.Email})
+}
+
+// 方法名称与接口文档的映射
+func (a *Api) ApiDoc() map[string]ghttp.ApiDoc {
+    return map[string]ghttp.ApiDoc {
+        "Show" : {Summary : "用户详情", Response : User{}},
+        "Save" : {Summary : "保存用户", Request : UserReq{}, Response : User{}},
+    }
+}
+
+// 访问 http://127.0.0.1:8199/swagger 查看接口文档
+func main() {
+    s := g.Server()
+    s.BindObject("/user/{.method}/:id", new(Api))
+    s.BindHandler("/hello", func(r *ghttp.Request) {
+        r.Response.Write("hello")
+    })
+    s.SetApiDoc("/hello", ghttp.ApiDoc{Summary : "hello world"})
+    s.SetOpenApiInfo(ghttp.OpenApiInfo{Title : "Demo API", Version : "1.0.0"})
+    s.EnableOpenApi()
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+    "time"
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+func main() {
+    s := g.Server()
+    // 反向代理，/api/下的请求去掉/api前缀后按照3:1的权重转发到两个上游服务
+    s.BindProxy("/api/*any", ghttp.ProxyConfig {
+        Upstreams       : []string{"http://127.0.0.1:8200#3", "http://127.0.0.1:8201#1"},
+        Balance         : ghttp.PROXY_BALANCE_WEIGHT,
+        StripPrefix     : "/api",
+        RequestHeaders  : map[string]string{"X-From": "gf-proxy"},
+        ResponseHeaders : map[string]string{"X-Powered-By": ""},
+        ResponseTimeout : 5*time.Second,
+    })
+    // 本地代理，/u/100 在服务内部交给 /user/100/profile 处理
+    s.BindHandler("/user/:id/profile", func(r *ghttp.Request) {
+        r.Response.Write("profile of user ", r.Get("id"))
+    })
+    s.BindProxyLocal("/u/:id", "/user/{id}/profile")
+    s.SetPort(8199)
+    s.Run()
+}
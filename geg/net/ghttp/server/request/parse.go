@@ -0,0 +1,33 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+    "gitee.com/johng/gf/g/util/gvalid"
+)
+
+type RegisterReq struct {
+    Name  string `gvalid:"name@required|length:6,30#请输入账号|账号长度为:min到:max位"`
+    Pass  string `gvalid:"pass@required|length:6,30#请输入密码|密码长度不够"`
+    Pass2 string `gvalid:"pass2@required|same:pass#请确认密码|两次密码不一致"`
+    Tags  []string
+}
+
+// curl -d '{"name":"johngcn","pass":"123456","pass2":"123456","tags":["a","b"]}' -H "Content-Type: application/json" http://127.0.0.1:8199/register
+func main() {
+    s := g.Server()
+    s.BindHandler("/register", func(r *ghttp.Request) {
+        req := new(RegisterReq)
+        if err := r.Parse(req); err != nil {
+            if e, ok := err.(gvalid.Error); ok {
+                r.Response.WriteJson(g.Map{"error": e.Strings()})
+            } else {
+                r.Response.WriteJson(g.Map{"error": err.Error()})
+            }
+            return
+        }
+        r.Response.WriteJson(req)
+    })
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// 上传文件示例：curl -F "file=@/tmp/test.png" http://127.0.0.1:8199/upload
+func main() {
+    s := g.Server()
+    // 请求体最大10MB，单个文件最大2MB
+    s.SetClientMaxBodySize(10 << 20)
+    s.SetUploadMaxFileSize(2 << 20)
+    s.BindHandler("/upload", func(r *ghttp.Request) {
+        file, err := r.GetUploadFile("file")
+        if err != nil {
+            r.Response.Write(err.Error())
+            return
+        }
+        if file == nil {
+            r.Response.Write("no file uploaded")
+            return
+        }
+        name, err := file.Save("/tmp/upload", true)
+        if err != nil {
+            r.Response.Write(err.Error())
+            return
+        }
+        r.Response.Writef("%s(%s, %d bytes) saved as %s", file.Name(), file.ContentType(), file.Size, name)
+    })
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// 路由表输出，访问 http://127.0.0.1:8199/debug/admin/routes 查看路由表页面
+func main() {
+    s := g.Server()
+    s.BindHandler("/user/list", func(r *ghttp.Request) {
+        r.Response.Write("list")
+    })
+    s.BindHandler("/user/:id", func(r *ghttp.Request) {
+        r.Response.Write("id")
+    })
+    // 该路由优先级比/user/:id高，会覆盖/user/:id路由，路由表中将会标记出来
+    s.BindHandler("/user/{name}", func(r *ghttp.Request) {
+        r.Response.Write("name")
+    })
+    s.BindHookHandler("/user/*", ghttp.HOOK_BEFORE_SERVE, func(r *ghttp.Request) {
+        r.Response.Header().Set("X-User", "1")
+    })
+    s.EnableAdmin()
+    s.DumpRoutes()
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+type Order struct {}
+
+func (o *Order) List(r *ghttp.Request) {
+    r.Response.Write("order list")
+}
+
+func (o *Order) Show(r *ghttp.Request) {
+    r.Response.Write("order:", r.Get("id"))
+}
+
+func main() {
+    s := g.Server()
+    // 命名路由，通过路由名称反向生成URL，路由规则变化时不需要修改引用的地方
+    s.BindHandler("/user/:id/{field}.html", func(r *ghttp.Request) {
+        r.Response.Write("user:", r.Get("id"), ", field:", r.Get("field"))
+    }, "user.field")
+    s.BindHandler("/", func(r *ghttp.Request) {
+        url, _ := r.Server.URLFor("user.field", g.Map{"id" : 1, "field" : "name"})
+        r.Response.WriteTplContent(`<a href="{{url "user.field" "id" 2 "field" "age"}}">age</a> `, g.Map{})
+        r.Response.Write(`<a href="` + url + `">name</a>`)
+    })
+    // 对象注册时每个方法的路由名称为：名称前缀.方法名称，即order.List及order.Show
+    s.BindObjectWithName("/order/{.method}/:id", new(Order), "order")
+    s.BindHandler("/order-jump", func(r *ghttp.Request) {
+        r.Response.RedirectRoute("order.Show", g.Map{"id" : 100})
+    })
+    s.BindHandler("/jump", func(r *ghttp.Request) {
+        r.Response.RedirectRoute("user.field", g.Map{"id" : 3, "field" : "nickname"})
+    })
+    s.SetPort(8199)
+    s.Run()
+}
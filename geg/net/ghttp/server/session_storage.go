@@ -0,0 +1,26 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+    "gitee.com/johng/gf/g/database/gredis"
+)
+
+func main() {
+    s := g.Server()
+    // 使用文件存储Session，服务重启后Session数据不会丢失
+    //s.SetSessionStorage(ghttp.NewSessionStorageFile("/tmp/gsessions"))
+    // 使用Redis存储Session，多个服务实例之间可以共享Session
+    s.SetSessionStorage(ghttp.NewSessionStorageRedis(gredis.Config{
+        Host : "127.0.0.1",
+        Port : 6379,
+        Db   : 1,
+    }))
+    s.BindHandler("/session", func(r *ghttp.Request) {
+        id := r.Session.GetInt("id")
+        r.Session.Set("id", id + 1)
+        r.Response.Write("id:", id)
+    })
+    s.SetPort(8199)
+    s.Run()
+}
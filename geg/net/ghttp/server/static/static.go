@@ -0,0 +1,23 @@
+package main
+
+import (
+    "net/http"
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// 静态文件服务：缓存控制、预压缩文件(.br/.gz)、Range请求(视频拖动/断点续传)及自定义文件系统
+func main() {
+    s := g.Server()
+    s.SetServerRoot("/home/www/static")
+    s.SetIndexFolder(true)
+    // 优先输出同目录下预先压缩好的app.js.br/app.js.gz文件
+    s.SetStaticPrecompressed(true)
+    // 带版本号的资源文件长期缓存，其他文件每次请求都需要校验(ETag/Last-Modified)
+    s.SetStaticCache("/",       ghttp.StaticCacheConfig{CacheControl : "no-cache"})
+    s.SetStaticCache("/assets", ghttp.StaticCacheConfig{CacheControl : "public, max-age=31536000, immutable"})
+    // 将/bundle前缀映射到文件系统，可以是任意http.FileSystem实现，如编译到二进制中的资源包
+    s.AddStaticFS("/bundle", http.Dir("/home/www/bundle"))
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+    "time"
+    "strconv"
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// Server-Sent Events示例，浏览器端：new EventSource("/events")
+func main() {
+    s := g.Server()
+    s.BindHandler("/events", func(r *ghttp.Request){
+        sse := r.Response.SSE()
+        sse.Heartbeat(15*time.Second)
+        for i := 0; ; i++ {
+            err := sse.Send(ghttp.SSEEvent{
+                Id    : strconv.Itoa(i),
+                Event : "time",
+                Data  : g.Map{"time" : time.Now().String()},
+            })
+            if err != nil {
+                return
+            }
+            select {
+                case <- sse.Done():
+                    return
+                case <- time.After(time.Second):
+            }
+        }
+    })
+    s.SetPort(8199)
+    s.Run()
+}
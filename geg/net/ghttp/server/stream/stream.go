@@ -0,0 +1,27 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// 流式输出示例，每次Flush都会将缓冲区内容立即发送到客户端
+func main() {
+    s := g.Server()
+    s.BindHandler("/export", func(r *ghttp.Request){
+        r.Response.Header().Set("Content-Type", "text/csv")
+        r.Response.Header().Set("Content-Disposition", "attachment; filename=export.csv")
+        r.Response.Writeln("id,name")
+        for i := 1; i <= 100000; i++ {
+            r.Response.Writefln("%d,name%d", i, i)
+            if i % 1000 == 0 {
+                if err := r.Response.Flush(); err != nil {
+                    // 客户端已断开
+                    return
+                }
+            }
+        }
+    })
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// 请求ID及链路追踪示例，
+// 访问日志、错误日志以及r.Log()/glog.Ctx(r.Context())输出的日志都会带上请求ID，
+// 通过Client.WithContext(r.Context())或者r.Client()发起的请求会将请求ID传递给下游服务
+func main() {
+    s := g.Server()
+    s.SetAccessLogEnabled(true)
+    s.BindHandler("/", func(r *ghttp.Request){
+        r.Log().Println("request received")
+        if resp, err := ghttp.NewClient().WithContext(r.Context()).Get("http://127.0.0.1:8199/downstream"); err == nil {
+            defer resp.Close()
+            r.Response.Write(resp.ReadAll())
+        }
+    })
+    s.BindHandler("/downstream", func(r *ghttp.Request){
+        glog.Ctx(r.Context()).Println("downstream request received")
+        go func() {
+            r.Log().Println("downstream goroutine")
+        }()
+        r.Response.Writeln("request id:", r.GetRequestId())
+        r.Response.Writeln("trace id  :", r.GetTraceId())
+        r.Response.Writeln("parent    :", r.Header.Get("traceparent"))
+    })
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+    "time"
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// 聊天室示例：ws://127.0.0.1:8199/ws?room=test
+func main() {
+    s   := g.Server()
+    hub := s.EnableWebSocketHub(ghttp.WebSocketHubConfig {
+        PingInterval : 10*time.Second,
+        OnConnect    : func(c *ghttp.WebSocketClient) {
+            room := c.Request.GetQueryString("room")
+            if room == "" {
+                room = "default"
+            }
+            c.Join(room)
+            c.SendText("welcome, your id: " + c.Id)
+        },
+        OnMessage    : func(c *ghttp.WebSocketClient, messageType int, data []byte) {
+            for _, room := range c.Rooms() {
+                c.Request.Server.GetWebSocketHub().BroadcastRoom(room, messageType, data)
+            }
+        },
+    })
+    s.BindHandler("/ws", hub.Serve)
+    s.BindHandler("/online", func(r *ghttp.Request) {
+        r.Response.WriteJson(g.Map {
+            "count" : hub.Count(),
+            "rooms" : hub.Rooms(),
+        })
+    })
+    s.SetPort(8199)
+    s.Run()
+}